@@ -0,0 +1,289 @@
+package ast
+
+import "fmt"
+
+// Visitor - Visit is called for every node Walk encounters. if the returned visitor w
+// is not nil, Walk visits the children of node with w, followed by a call of w.Visit(nil)
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk - traverse the AST in depth-first order, children in source order.
+// starts with v.Visit(node), see Visitor
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	// statements
+	case *Program:
+		walkStatements(v, n.Statements)
+
+	case *LetStatement:
+		Walk(v, n.Name)
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+
+	case *ReturnStatement:
+		if n.ReturnValue != nil {
+			Walk(v, n.ReturnValue)
+		}
+
+	case *ExpressionStatement:
+		if n.Expression != nil {
+			Walk(v, n.Expression)
+		}
+
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+
+	// expressions
+	case *PrefixExpression:
+		Walk(v, n.Right)
+
+	case *InfixExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case *IfExpression:
+		Walk(v, n.Condition)
+		Walk(v, n.Consequence)
+		if n.Alternative != nil {
+			Walk(v, n.Alternative)
+		}
+
+	case *FunctionLiteral:
+		for _, p := range n.Parameters {
+			Walk(v, p)
+		}
+		Walk(v, n.Body)
+
+	case *CallExpression:
+		Walk(v, n.Function)
+		walkExpressions(v, n.Arguments)
+
+	case *ArrayLiteral:
+		walkExpressions(v, n.Elements)
+
+	case *IndexExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Index)
+
+	case *HashLiteral:
+		for _, pair := range n.Pairs {
+			Walk(v, pair.Key)
+			Walk(v, pair.Value)
+		}
+
+	// leaves
+	case *Identifier, *IntegerLiteral, *FloatLiteral, *StringLiteral, *Boolean,
+		*BadExpression, *BadStatement:
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkStatements(v Visitor, list []Statement) {
+	for _, s := range list {
+		Walk(v, s)
+	}
+}
+
+func walkExpressions(v Visitor, list []Expression) {
+	for _, e := range list {
+		Walk(v, e)
+	}
+}
+
+// inspector - turns a func(Node) bool into a Visitor
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect - traverse the AST in depth-first order, calling f(node) for every node.
+// the children of node are only visited if f returns true. after the children,
+// f(nil) is called, e.g:
+//
+//	ast.Inspect(program, func(n ast.Node) bool {
+//		if ident, ok := n.(*ast.Identifier); ok {
+//			fmt.Println(ident.Value)
+//		}
+//		return true
+//	})
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// rewriter - state of Rewrite
+type rewriter struct {
+	pre  func(Node) Node
+	post func(Node) Node
+}
+
+// Rewrite - traverse the AST in depth-first order and replace every node with the result of
+// pre (called before the children of the node are rewritten) and post (called after).
+// either of them may be nil. the children of the node returned by pre are rewritten, so pre
+// sees the original node and post the one with rewritten children. the tree is modified in place,
+// the (possibly replaced) root is returned.
+//
+// a replacement has to fit where the node was: expressions are replaced by expressions, statements by
+// statements, identifiers in let statements and parameters by identifiers and blocks by blocks.
+// returning nil removes a statement from its program or block. anything else panics, e.g:
+//
+//	// constant folding of additions
+//	ast.Rewrite(program, nil, func(n ast.Node) ast.Node {
+//		infix, ok := n.(*ast.InfixExpression)
+//		if !ok || infix.Operator != "+" {
+//			return n
+//		}
+//		left, lok := infix.Left.(*ast.IntegerLiteral)
+//		right, rok := infix.Right.(*ast.IntegerLiteral)
+//		if !lok || !rok {
+//			return n
+//		}
+//		value := left.Value + right.Value
+//		tok := left.Token
+//		tok.Literal = strconv.FormatInt(value, 10)
+//		return &ast.IntegerLiteral{Token: tok, Value: value}
+//	})
+func Rewrite(node Node, pre, post func(Node) Node) Node {
+	r := &rewriter{pre: pre, post: post}
+	return r.rewrite(node)
+}
+
+func (r *rewriter) rewrite(node Node) Node {
+	if r.pre != nil {
+		if node = r.pre(node); node == nil {
+			return nil
+		}
+	}
+
+	switch n := node.(type) {
+	// statements
+	case *Program:
+		n.Statements = r.statements(n.Statements)
+
+	case *LetStatement:
+		n.Name = r.identifier(n.Name)
+		if n.Value != nil {
+			n.Value = r.expression(n.Value)
+		}
+
+	case *ReturnStatement:
+		if n.ReturnValue != nil {
+			n.ReturnValue = r.expression(n.ReturnValue)
+		}
+
+	case *ExpressionStatement:
+		if n.Expression != nil {
+			n.Expression = r.expression(n.Expression)
+		}
+
+	case *BlockStatement:
+		n.Statements = r.statements(n.Statements)
+
+	// expressions
+	case *PrefixExpression:
+		n.Right = r.expression(n.Right)
+
+	case *InfixExpression:
+		n.Left = r.expression(n.Left)
+		n.Right = r.expression(n.Right)
+
+	case *IfExpression:
+		n.Condition = r.expression(n.Condition)
+		n.Consequence = r.block(n.Consequence)
+		if n.Alternative != nil {
+			n.Alternative = r.block(n.Alternative)
+		}
+
+	case *FunctionLiteral:
+		for i, p := range n.Parameters {
+			n.Parameters[i] = r.identifier(p)
+		}
+		n.Body = r.block(n.Body)
+
+	case *CallExpression:
+		n.Function = r.expression(n.Function)
+		r.expressions(n.Arguments)
+
+	case *ArrayLiteral:
+		r.expressions(n.Elements)
+
+	case *IndexExpression:
+		n.Left = r.expression(n.Left)
+		n.Index = r.expression(n.Index)
+
+	case *HashLiteral:
+		for i, pair := range n.Pairs {
+			n.Pairs[i] = HashPair{Key: r.expression(pair.Key), Value: r.expression(pair.Value)}
+		}
+
+	// leaves
+	case *Identifier, *IntegerLiteral, *FloatLiteral, *StringLiteral, *Boolean,
+		*BadExpression, *BadStatement:
+
+	default:
+		panic(fmt.Sprintf("ast.Rewrite: unexpected node type %T", n))
+	}
+
+	if r.post != nil {
+		node = r.post(node)
+	}
+	return node
+}
+
+// statements - rewrite a list of statements, dropping the ones replaced by nil
+func (r *rewriter) statements(list []Statement) []Statement {
+	result := list[:0]
+	for _, s := range list {
+		switch n := r.rewrite(s).(type) {
+		case nil:
+		case Statement:
+			result = append(result, n)
+		default:
+			panic(fmt.Sprintf("ast.Rewrite: statement %q replaced by %T", s.String(), n))
+		}
+	}
+	return result
+}
+
+func (r *rewriter) expressions(list []Expression) {
+	for i, e := range list {
+		list[i] = r.expression(e)
+	}
+}
+
+func (r *rewriter) expression(e Expression) Expression {
+	n, ok := r.rewrite(e).(Expression)
+	if !ok {
+		panic(fmt.Sprintf("ast.Rewrite: expression %q replaced by a node that is not an expression", e.String()))
+	}
+	return n
+}
+
+func (r *rewriter) identifier(ident *Identifier) *Identifier {
+	n, ok := r.rewrite(ident).(*Identifier)
+	if !ok {
+		panic(fmt.Sprintf("ast.Rewrite: identifier %q replaced by a node that is not an identifier", ident.Value))
+	}
+	return n
+}
+
+func (r *rewriter) block(block *BlockStatement) *BlockStatement {
+	n, ok := r.rewrite(block).(*BlockStatement)
+	if !ok {
+		panic("ast.Rewrite: block statement replaced by a node that is not a block statement")
+	}
+	return n
+}
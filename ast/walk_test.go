@@ -0,0 +1,233 @@
+// the walker tests parse real programs, which needs the parser. the parser imports
+// this package, so these tests live in the external ast_test package
+package ast_test
+
+import (
+	"fmt"
+	"monkeylang/ast"
+	"monkeylang/lexer"
+	"monkeylang/parser"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	return p.ParseProgram()
+}
+
+// nodeTypes - the type of every node Inspect visits, in order
+func nodeTypes(node ast.Node) []string {
+	types := []string{}
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n != nil {
+			types = append(types, strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast."))
+		}
+		return true
+	})
+	return types
+}
+
+func TestInspectOrder(t *testing.T) {
+	program := parse(t, "let x = 1 + y; return -x;")
+
+	expected := "Program LetStatement Identifier InfixExpression IntegerLiteral Identifier " +
+		"ReturnStatement PrefixExpression Identifier"
+	if got := strings.Join(nodeTypes(program), " "); got != expected {
+		t.Errorf("wrong order. expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestWalkCoversEveryNodeType(t *testing.T) {
+	input := `
+let add = fn(a, b) { return a + b; };
+if (!true) { add(1, 2.5) } else { [1, "two"][0] };
+{"key": false};
+`
+	seen := make(map[string]bool)
+	for _, typ := range nodeTypes(parse(t, input)) {
+		seen[typ] = true
+	}
+	// broken input produces placeholders for the missing parts
+	for _, typ := range nodeTypes(parse(t, "let = 5; let x = ;")) {
+		seen[typ] = true
+	}
+
+	expected := []string{
+		"Program", "LetStatement", "ReturnStatement", "ExpressionStatement", "BlockStatement",
+		"Identifier", "IntegerLiteral", "FloatLiteral", "StringLiteral", "Boolean",
+		"PrefixExpression", "InfixExpression", "IfExpression", "FunctionLiteral", "CallExpression",
+		"ArrayLiteral", "IndexExpression", "HashLiteral", "BadStatement", "BadExpression",
+	}
+	for _, typ := range expected {
+		if !seen[typ] {
+			t.Errorf("%s is not visited", typ)
+		}
+	}
+}
+
+// depthVisitor - records the deepest nesting, checking every node is followed by a Visit(nil)
+type depthVisitor struct {
+	depth, maxDepth *int
+}
+
+func (v depthVisitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		*v.depth--
+		return nil
+	}
+	*v.depth++
+	if *v.depth > *v.maxDepth {
+		*v.maxDepth = *v.depth
+	}
+	return v
+}
+
+func TestWalkVisitsNilAfterChildren(t *testing.T) {
+	depth, maxDepth := 0, 0
+	ast.Walk(depthVisitor{&depth, &maxDepth}, parse(t, "if (x) { 1 + 2 }"))
+
+	if depth != 0 {
+		t.Errorf("Visit(nil) is not called once per node. depth=%d", depth)
+	}
+	// Program > ExpressionStatement > IfExpression > BlockStatement > ExpressionStatement > InfixExpression > IntegerLiteral
+	if maxDepth != 7 {
+		t.Errorf("wrong max depth. expected=7, got=%d", maxDepth)
+	}
+}
+
+func TestInspectPrune(t *testing.T) {
+	program := parse(t, "let f = fn(a) { a * b }; f(c);")
+
+	idents := []string{}
+	ast.Inspect(program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FunctionLiteral:
+			return false
+		case *ast.Identifier:
+			idents = append(idents, n.Value)
+		}
+		return true
+	})
+
+	if got := strings.Join(idents, ","); got != "f,f,c" {
+		t.Errorf("wrong identifiers. expected=%q, got=%q", "f,f,c", got)
+	}
+}
+
+// fold - post order constant folding of integer arithmetic
+func fold(n ast.Node) ast.Node {
+	infix, ok := n.(*ast.InfixExpression)
+	if !ok {
+		return n
+	}
+	left, lok := infix.Left.(*ast.IntegerLiteral)
+	right, rok := infix.Right.(*ast.IntegerLiteral)
+	if !lok || !rok {
+		return n
+	}
+
+	var value int64
+	switch infix.Operator {
+	case "+":
+		value = left.Value + right.Value
+	case "-":
+		value = left.Value - right.Value
+	case "*":
+		value = left.Value * right.Value
+	default:
+		return n
+	}
+	// String() prints the token literal, so the token has to match the new value
+	tok := left.Token
+	tok.Literal = strconv.FormatInt(value, 10)
+	return &ast.IntegerLiteral{Token: tok, Value: value}
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		input     string
+		pre, post func(ast.Node) ast.Node
+		expected  string
+	}{
+		{
+			"let x = 1 + 2 * 3; fn(a) { a + (4 - 1) }; [2 * 2, {1 + 1: 3 * 3}][0]",
+			nil, fold,
+			"let x = 7;fn(a) (a + 3)([4, {2: 9}][0])",
+		},
+		{
+			// pre sees the original nodes, so only the innermost addition can be folded
+			"1 + 2 + 3",
+			fold, nil,
+			"(3 + 3)",
+		},
+		{
+			"let a = 1; let f = fn(a) { a }; f(a);",
+			func(n ast.Node) ast.Node {
+				if ident, ok := n.(*ast.Identifier); ok && ident.Value == "a" {
+					return &ast.Identifier{Token: ident.Token, Value: "b"}
+				}
+				return n
+			},
+			nil,
+			"let b = 1;let f = fn(b) b;f(b)",
+		},
+		{
+			// nil removes statements, also inside blocks
+			"1; let x = 2; if (x) { 3; x }; 4",
+			func(n ast.Node) ast.Node {
+				if es, ok := n.(*ast.ExpressionStatement); ok {
+					if _, ok := es.Expression.(*ast.IntegerLiteral); ok {
+						return nil
+					}
+				}
+				return n
+			},
+			nil,
+			"let x = 2;ifx x",
+		},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		result := ast.Rewrite(program, tt.pre, tt.post)
+
+		if result.String() != tt.expected {
+			t.Errorf("input %q: wrong result. expected=%q, got=%q", tt.input, tt.expected, result.String())
+		}
+	}
+}
+
+func TestRewriteRoot(t *testing.T) {
+	result := ast.Rewrite(parse(t, "1"), nil, func(n ast.Node) ast.Node {
+		if _, ok := n.(*ast.Program); ok {
+			return &ast.Program{}
+		}
+		return n
+	})
+
+	if program, ok := result.(*ast.Program); !ok || len(program.Statements) != 0 {
+		t.Errorf("root is not replaced. got=%T (%q)", result, result.String())
+	}
+}
+
+func TestRewriteInvalidReplacement(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("replacing an identifier by an integer does not panic")
+		}
+		if !strings.Contains(fmt.Sprint(r), `identifier "x" replaced`) {
+			t.Errorf("wrong panic message. got=%q", r)
+		}
+	}()
+
+	ast.Rewrite(parse(t, "let x = 1;"), func(n ast.Node) ast.Node {
+		if ident, ok := n.(*ast.Identifier); ok {
+			return &ast.IntegerLiteral{Token: ident.Token, Value: 1}
+		}
+		return n
+	}, nil)
+}
@@ -0,0 +1,150 @@
+package evaluator
+
+import (
+	"fmt"
+	"monkeylang/object"
+	"sort"
+)
+
+// builtins - functions available to every program. identifiers are only
+// looked up here if they are not bound in the environment
+var builtins = map[string]*object.Builtin{}
+
+func init() {
+	RegisterBuiltin("len", builtinLen)
+	RegisterBuiltin("puts", builtinPuts)
+	RegisterBuiltin("first", builtinFirst)
+	RegisterBuiltin("last", builtinLast)
+	RegisterBuiltin("rest", builtinRest)
+	RegisterBuiltin("push", builtinPush)
+	RegisterBuiltin("type", builtinType)
+}
+
+// RegisterBuiltin - make fn available to every program under name, replacing
+// any builtin of the same name. go programs embedding the interpreter use this to
+// expose their own functions. registration is not synchronized: register builtins
+// before evaluating programs (e.g: in an init function)
+func RegisterBuiltin(name string, fn object.BuiltinFunction) {
+	builtins[name] = &object.Builtin{Name: name, Fn: fn}
+}
+
+// BuiltinNames - names of all registered builtins, sorted
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkArgs - report a wrong number of arguments the same way user defined functions do
+func checkArgs(args []object.Object, want int) *object.Error {
+	if len(args) != want {
+		return newError(object.ArgumentError, "wrong number of arguments: want=%d, got=%d", want, len(args))
+	}
+	return nil
+}
+
+// builtinLen - number of characters in a string or elements in an array or hash
+func builtinLen(_ *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs(args, 1); err != nil {
+		return err
+	}
+
+	switch arg := args[0].(type) {
+	case *object.String:
+		return &object.Integer{Value: int64(len([]rune(arg.Value)))}
+	case *object.Array:
+		return &object.Integer{Value: int64(len(arg.Elements))}
+	case *object.Hash:
+		return &object.Integer{Value: int64(len(arg.Pairs))}
+	default:
+		return newError(object.TypeError, "argument to `len` not supported, got %s", args[0].Type())
+	}
+}
+
+// builtinPuts - print every argument on its own line, to the output of env
+func builtinPuts(env *object.Environment, args ...object.Object) object.Object {
+	for _, arg := range args {
+		fmt.Fprintln(env.Output(), arg.Inspect())
+	}
+	return NULL
+}
+
+// builtinFirst - first element of an array, null if it is empty
+func builtinFirst(_ *object.Environment, args ...object.Object) object.Object {
+	arr, err := arrayArg("first", args)
+	if err != nil {
+		return err
+	}
+	if len(arr.Elements) > 0 {
+		return arr.Elements[0]
+	}
+	return NULL
+}
+
+// builtinLast - last element of an array, null if it is empty
+func builtinLast(_ *object.Environment, args ...object.Object) object.Object {
+	arr, err := arrayArg("last", args)
+	if err != nil {
+		return err
+	}
+	if length := len(arr.Elements); length > 0 {
+		return arr.Elements[length-1]
+	}
+	return NULL
+}
+
+// builtinRest - new array with every element but the first, null if it is empty
+func builtinRest(_ *object.Environment, args ...object.Object) object.Object {
+	arr, err := arrayArg("rest", args)
+	if err != nil {
+		return err
+	}
+	length := len(arr.Elements)
+	if length == 0 {
+		return NULL
+	}
+
+	elements := make([]object.Object, length-1)
+	copy(elements, arr.Elements[1:])
+	return &object.Array{Elements: elements}
+}
+
+// builtinPush - new array with the second argument appended. arrays are never modified in place
+func builtinPush(_ *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs(args, 2); err != nil {
+		return err
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError(object.TypeError, "argument to `push` must be ARRAY, got %s", args[0].Type())
+	}
+
+	length := len(arr.Elements)
+	elements := make([]object.Object, length+1)
+	copy(elements, arr.Elements)
+	elements[length] = args[1]
+	return &object.Array{Elements: elements}
+}
+
+// builtinType - name of the type of the argument as a string (e.g: "INTEGER")
+func builtinType(_ *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs(args, 1); err != nil {
+		return err
+	}
+	return &object.String{Value: string(args[0].Type())}
+}
+
+// arrayArg - the single array argument of first, last and rest
+func arrayArg(name string, args []object.Object) (*object.Array, *object.Error) {
+	if err := checkArgs(args, 1); err != nil {
+		return nil, err
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, newError(object.TypeError, "argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	return arr, nil
+}
@@ -0,0 +1,139 @@
+package evaluator
+
+import (
+	"bytes"
+	"fmt"
+	"monkeylang/lexer"
+	"monkeylang/object"
+	"monkeylang/parser"
+	"sync"
+	"testing"
+)
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len("héllo")`, 5},
+		{`len([1, 2, 3])`, 3},
+		{`len({"a": 1, "b": 2})`, 2},
+		{`len(1)`, "TypeError: argument to `len` not supported, got INTEGER at 1:1"},
+		{`len("one", "two")`, "ArgumentError: wrong number of arguments: want=1, got=2 at 1:1"},
+		{`first([1, 2, 3])`, 1},
+		{`first([])`, nil},
+		{`first(1)`, "TypeError: argument to `first` must be ARRAY, got INTEGER at 1:1"},
+		{`last([1, 2, 3])`, 3},
+		{`last([])`, nil},
+		{`rest([1, 2, 3])`, []int64{2, 3}},
+		{`rest([1])`, []int64{}},
+		{`rest([])`, nil},
+		{`push([], 1)`, []int64{1}},
+		{`let a = [1]; push(a, 2); a`, []int64{1}},
+		{`push(1, 1)`, "TypeError: argument to `push` must be ARRAY, got INTEGER at 1:1"},
+		{`puts("hello")`, nil},
+		{`type(1)`, "INTEGER"},
+		{`type("a")`, "STRING"},
+		{`type(len)`, "BUILTIN"},
+		{`let len = fn(x) { 42 }; len("a")`, 42},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case nil:
+			testNullObject(t, evaluated)
+		case []int64:
+			arr, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if len(arr.Elements) != len(expected) {
+				t.Errorf("wrong number of elements. want=%d, got=%d", len(expected), len(arr.Elements))
+				continue
+			}
+			for i, e := range expected {
+				testIntegerObject(t, arr.Elements[i], e)
+			}
+		case string:
+			switch obj := evaluated.(type) {
+			case *object.Error:
+				if obj.Inspect() != expected {
+					t.Errorf("wrong error. expected=%q, got=%q", expected, obj.Inspect())
+				}
+			case *object.String:
+				if obj.Value != expected {
+					t.Errorf("wrong string. expected=%q, got=%q", expected, obj.Value)
+				}
+			default:
+				t.Errorf("object is not Error or String. got=%T (%+v)", evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestPutsOutput(t *testing.T) {
+	// every program writes to the output of its own environment, even when they run concurrently
+	outputs := make([]bytes.Buffer, 4)
+	var wg sync.WaitGroup
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			input := fmt.Sprintf(`let say = fn(x) { puts(x) }; puts("start"); say(%d)`, i)
+			program := parser.New(lexer.New(input)).ParseProgram()
+			env := object.NewEnvironment()
+			env.SetOutput(&outputs[i])
+			Eval(program, env)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, out := range outputs {
+		expected := fmt.Sprintf("start\n%d\n", i)
+		if out.String() != expected {
+			t.Errorf("wrong output of program %d. expected=%q, got=%q", i, expected, out.String())
+		}
+	}
+}
+
+func TestRegisterBuiltin(t *testing.T) {
+	RegisterBuiltin("config", func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return object.NewError(object.ArgumentError, "wrong number of arguments: want=1, got=%d", len(args))
+		}
+		key, ok := args[0].(*object.String)
+		if !ok || key.Value != "port" {
+			return nil
+		}
+		return &object.Integer{Value: 8080}
+	})
+	defer delete(builtins, "config")
+
+	found := false
+	for _, name := range BuiltinNames() {
+		found = found || name == "config"
+	}
+	if !found {
+		t.Errorf("registered builtin is not in BuiltinNames(). got=%v", BuiltinNames())
+	}
+
+	testIntegerObject(t, testEval(`config("port") + 1`), 8081)
+	testNullObject(t, testEval(`config("host")`))
+
+	evaluated := testEval(`let f = fn() { config() }; f()`)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	expected := "ArgumentError: wrong number of arguments: want=1, got=0 at 1:16\n  in f, called at 1:28"
+	if errObj.Inspect() != expected {
+		t.Errorf("wrong error. expected=%q, got=%q", expected, errObj.Inspect())
+	}
+}
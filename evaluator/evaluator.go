@@ -0,0 +1,626 @@
+package evaluator
+
+import (
+	"math"
+	"monkeylang/ast"
+	"monkeylang/object"
+	"monkeylang/token"
+)
+
+// maxCallDepth - function calls can be nested this deep. deeper calls fail with a RuntimeError,
+// instead of overflowing the go stack (which can not be recovered from)
+const maxCallDepth = 10000
+
+// there is only ever one true, false and null - no need to allocate new ones on every evaluation
+var (
+	NULL  = &object.Null{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+)
+
+// Eval - tree-walking evaluator. takes an AST node and the environment it is
+// evaluated in, and returns the object the node produces.
+// errors are tagged with the position of the innermost node that produced them
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	result := eval(node, env)
+	if err, ok := result.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos = nodePos(node)
+	}
+	return result
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	// statements
+	case *ast.Program:
+		return evalProgram(node, env)
+
+	case *ast.ExpressionStatement:
+		return Eval(node.Expression, env)
+
+	case *ast.BlockStatement:
+		return evalBlockStatement(node, env)
+
+	case *ast.LetStatement:
+		val := evalOptional(node.Value, env)
+		if isStop(val) {
+			return val
+		}
+		// name anonymous functions after the first binding, so they show up in stack traces
+		if fn, ok := val.(*object.Function); ok && fn.Name == "" {
+			fn.Name = node.Name.Value
+		}
+		env.Set(node.Name.Value, val)
+
+	case *ast.ReturnStatement:
+		val := evalOptional(node.ReturnValue, env)
+		if isStop(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+
+	// expressions
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}
+
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+
+	case *ast.Identifier:
+		return evalIdentifier(node, env)
+
+	case *ast.PrefixExpression:
+		right := Eval(node.Right, env)
+		if isStop(right) {
+			return right
+		}
+		return evalPrefixExpression(node.Operator, right)
+
+	case *ast.InfixExpression:
+		left := Eval(node.Left, env)
+		if isStop(left) {
+			return left
+		}
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, left, env)
+		}
+		right := Eval(node.Right, env)
+		if isStop(right) {
+			return right
+		}
+		return evalInfixExpression(node.Operator, left, right)
+
+	case *ast.IfExpression:
+		return evalIfExpression(node, env)
+
+	case *ast.FunctionLiteral:
+		return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env}
+
+	case *ast.CallExpression:
+		function := Eval(node.Function, env)
+		if isStop(function) {
+			return function
+		}
+		args := evalExpressions(node.Arguments, env)
+		if len(args) == 1 && isStop(args[0]) {
+			return args[0]
+		}
+		return applyFunction(node, function, args, env)
+
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isStop(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if isStop(left) {
+			return left
+		}
+		index := Eval(node.Index, env)
+		if isStop(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+
+	default:
+		return newError(object.RuntimeError, "unknown node: %T", node)
+	}
+
+	return nil
+}
+
+// evalProgram - evaluate every statement, stop early on return statements and errors.
+// the return value is unwrapped here, since there is nothing left to return from
+func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range program.Statements {
+		result = Eval(statement, env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *object.Error:
+			return result
+		}
+	}
+
+	return result
+}
+
+// evalBlockStatement - same as evalProgram, except return values are not unwrapped.
+// that way a return inside a nested block stops the outer blocks as well
+func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range block.Statements {
+		result = Eval(statement, env)
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// evalOptional - let and return statements may not have a value, evaluate those to null
+func evalOptional(exp ast.Expression, env *object.Environment) object.Object {
+	if exp == nil {
+		return NULL
+	}
+	return Eval(exp, env)
+}
+
+func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+	// builtins are only used if the name is not bound, so scripts can shadow them
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
+	}
+
+	return newError(object.NameError, "identifier not found: %s", node.Value)
+}
+
+// evalExpressions - evaluate expressions from left to right.
+// stops at the first error or return value, and returns it as the only element
+func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	var result []object.Object
+
+	for _, e := range exps {
+		evaluated := Eval(e, env)
+		if isStop(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+// evalHashLiteral - keys must be hashable (integers, booleans or strings).
+// a key that appears twice keeps the last value
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	pairs := make(map[object.HashKey]object.HashPair)
+
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isStop(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError(object.TypeError, "unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if isStop(value) {
+			return value
+		}
+
+		pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	return &object.Hash{Pairs: pairs}
+}
+
+// applyFunction - call fn with args. builtins are called directly (a nil result is null),
+// the body of other functions is evaluated in a new environment,
+// enclosed by the environment the function was defined in.
+// errors coming out of the body get a stack frame for this call appended.
+// env is the environment of the call, calls nested deeper than maxCallDepth fail
+func applyFunction(call *ast.CallExpression, fn object.Object, args []object.Object, env *object.Environment) object.Object {
+	if builtin, ok := fn.(*object.Builtin); ok {
+		return unwrapReturnValue(builtin.Fn(env, args...))
+	}
+
+	function, ok := fn.(*object.Function)
+	if !ok {
+		return newError(object.TypeError, "not a function: %s", fn.Type())
+	}
+
+	if len(args) != len(function.Parameters) {
+		return newError(object.ArgumentError, "wrong number of arguments: want=%d, got=%d", len(function.Parameters), len(args))
+	}
+
+	var evaluated object.Object
+	if env.CallDepth() >= maxCallDepth {
+		evaluated = newError(object.RuntimeError, "maximum recursion depth exceeded")
+	} else {
+		evaluated = Eval(function.Body, extendFunctionEnv(function, args, env))
+	}
+	if err, ok := evaluated.(*object.Error); ok {
+		err.Stack = append(err.Stack, object.StackFrame{Function: function.Name, Pos: callPos(call)})
+	}
+	return unwrapReturnValue(evaluated)
+}
+
+// callPos - position of a call site: the function name if the callee is an identifier,
+// the '(' token otherwise (e.g: fn(x) { x }(1))
+func callPos(call *ast.CallExpression) token.Position {
+	if ident, ok := call.Function.(*ast.Identifier); ok {
+		return ident.Token.Pos
+	}
+	return call.Token.Pos
+}
+
+// extendFunctionEnv - bind the arguments to the parameter names, in a new environment
+// enclosed by the one fn was defined in. caller is the environment of the call
+func extendFunctionEnv(fn *object.Function, args []object.Object, caller *object.Environment) *object.Environment {
+	env := object.NewCallEnvironment(fn.Env, caller)
+
+	for i, param := range fn.Parameters {
+		env.Set(param.Value, args[i])
+	}
+
+	return env
+}
+
+// unwrapReturnValue - a return statement only stops the function it is in,
+// not the caller. an empty body produces null
+func unwrapReturnValue(obj object.Object) object.Object {
+	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+	if obj == nil {
+		return NULL
+	}
+
+	return obj
+}
+
+// evalIndexExpression - arrays are indexed by integers, hashes by any hashable object
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.ARRAY_OBJ:
+		return newError(object.TypeError, "array index must be INTEGER, got %s", index.Type())
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError(object.TypeError, "index operator not supported: %s", left.Type())
+	}
+}
+
+// evalArrayIndexExpression - negative indices count from the end, e.g: [1, 2, 3][-1] is 3.
+// indices outside the array are an error
+func evalArrayIndexExpression(array, index object.Object) object.Object {
+	elements := array.(*object.Array).Elements
+	idx := index.(*object.Integer).Value
+	length := int64(len(elements))
+
+	i := idx
+	if i < 0 {
+		i += length
+	}
+	if i < 0 || i >= length {
+		return newError(object.IndexError, "index out of range: %d (array length %d)", idx, length)
+	}
+
+	return elements[i]
+}
+
+// evalHashIndexExpression - looking up a missing key produces null
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError(object.TypeError, "unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hashObject.Pairs[key.HashKey()]
+	if !ok {
+		return NULL
+	}
+
+	return pair.Value
+}
+
+// evalIfExpression - evaluates the consequence if the condition is truthy, the alternative otherwise.
+// an if expression without a matching branch produces null, so does a branch that is empty
+// or ends in a let statement
+func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := Eval(ie.Condition, env)
+	if isStop(condition) {
+		return condition
+	}
+
+	var result object.Object
+	if isTruthy(condition) {
+		result = Eval(ie.Consequence, env)
+	} else if ie.Alternative != nil {
+		result = Eval(ie.Alternative, env)
+	}
+
+	if result == nil {
+		return NULL
+	}
+	return result
+}
+
+// isTruthy - null and false are falsy, every other value is truthy
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL:
+		return false
+	case FALSE:
+		return false
+	default:
+		return true
+	}
+}
+
+// evalLogicalExpression - && and || short-circuit: the right operand is only evaluated
+// if the left one does not decide the result already. the result is always a boolean
+func evalLogicalExpression(node *ast.InfixExpression, left object.Object, env *object.Environment) object.Object {
+	if node.Operator == "&&" && !isTruthy(left) {
+		return FALSE
+	}
+	if node.Operator == "||" && isTruthy(left) {
+		return TRUE
+	}
+
+	right := Eval(node.Right, env)
+	if isStop(right) {
+		return right
+	}
+	return nativeBoolToBooleanObject(isTruthy(right))
+}
+
+func evalPrefixExpression(operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return evalBangOperatorExpression(right)
+	case "-":
+		return evalMinusPrefixOperatorExpression(right)
+	default:
+		return newError(object.TypeError, "unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+// evalBangOperatorExpression - !true is false, !null is true, everything else is truthy
+func evalBangOperatorExpression(right object.Object) object.Object {
+	switch right {
+	case TRUE:
+		return FALSE
+	case FALSE:
+		return TRUE
+	case NULL:
+		return TRUE
+	default:
+		return FALSE
+	}
+}
+
+func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
+		return newError(object.TypeError, "unknown operator: -%s", right.Type())
+	}
+}
+
+func evalInfixExpression(operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerInfixExpression(operator, left, right)
+	// mixed integer and float arithmetic is done in floats
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
+	// booleans and null are singletons, so pointer comparison is enough
+	case operator == "==":
+		return nativeBoolToBooleanObject(left == right)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(left != right)
+	case left.Type() != right.Type():
+		return newError(object.TypeError, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	default:
+		return newError(object.TypeError, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Integer).Value
+	rightVal := right.(*object.Integer).Value
+
+	switch operator {
+	case "+":
+		return &object.Integer{Value: leftVal + rightVal}
+	case "-":
+		return &object.Integer{Value: leftVal - rightVal}
+	case "*":
+		return &object.Integer{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError(object.ZeroDivisionError, "division by zero: %d / %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError(object.ZeroDivisionError, "division by zero: %d %% %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: leftVal % rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError(object.TypeError, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalFloatInfixExpression - at least one of the operands is a float, the other one may be an integer
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError(object.ZeroDivisionError, "division by zero: %s / %s", left.Inspect(), right.Inspect())
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError(object.ZeroDivisionError, "division by zero: %s %% %s", left.Inspect(), right.Inspect())
+		}
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError(object.TypeError, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat - expects obj to be a number (see isNumber())
+func toFloat(obj object.Object) float64 {
+	if integer, ok := obj.(*object.Integer); ok {
+		return float64(integer.Value)
+	}
+	return obj.(*object.Float).Value
+}
+
+// evalStringInfixExpression - strings support concatenation and comparison
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError(object.TypeError, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
+// nodePos - position an error produced by node is reported at. for operators this
+// is the operator token, so 'a + b' points at the '+'
+func nodePos(node ast.Node) token.Position {
+	switch node := node.(type) {
+	case *ast.Identifier:
+		return node.Token.Pos
+	case *ast.PrefixExpression:
+		return node.Token.Pos
+	case *ast.InfixExpression:
+		return node.Token.Pos
+	case *ast.IndexExpression:
+		return node.Token.Pos
+	case *ast.CallExpression:
+		return callPos(node)
+	case *ast.HashLiteral:
+		return node.Token.Pos
+	case *ast.ArrayLiteral:
+		return node.Token.Pos
+	case *ast.IfExpression:
+		return node.Token.Pos
+	case *ast.LetStatement:
+		return node.Token.Pos
+	case *ast.ReturnStatement:
+		return node.Token.Pos
+	case *ast.ExpressionStatement:
+		return node.Token.Pos
+	case *ast.BadExpression:
+		return node.Token.Pos
+	case *ast.BadStatement:
+		return node.Token.Pos
+	}
+	return token.Position{}
+}
+
+func newError(kind object.ErrorKind, format string, a ...interface{}) *object.Error {
+	return object.NewError(kind, format, a...)
+}
+
+// isStop - errors and return values (e.g: from 'if (x) { return 5 }' used as a value)
+// stop the evaluation of the surrounding expression and are passed up unchanged
+func isStop(obj object.Object) bool {
+	if obj != nil {
+		rt := obj.Type()
+		return rt == object.ERROR_OBJ || rt == object.RETURN_VALUE_OBJ
+	}
+	return false
+}
@@ -0,0 +1,748 @@
+package evaluator
+
+import (
+	"fmt"
+	"monkeylang/lexer"
+	"monkeylang/object"
+	"monkeylang/parser"
+	"testing"
+)
+
+func TestEvalIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"5", 5},
+		{"10", 10},
+		{"-5", -5},
+		{"-10", -10},
+		{"5 + 5 + 5 + 5 - 10", 10},
+		{"2 * 2 * 2 * 2 * 2", 32},
+		{"-50 + 100 + -50", 0},
+		{"5 * 2 + 10", 20},
+		{"5 + 2 * 10", 25},
+		{"20 + 2 * -10", 0},
+		{"50 / 2 * 2 + 10", 60},
+		{"10 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 + 10 % 4 * 3", 8},
+		{"2 * (5 + 10)", 30},
+		{"3 * 3 * 3 + 10", 37},
+		{"3 * (3 * 3) + 10", 37},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5", 1.5},
+		{"-2.5", -2.5},
+		{"1.5e-3", 0.0015},
+		{"0.5 + 0.25", 0.75},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"3 / 2.0", 1.5},
+		{"10 - 2.5 * 2", 5},
+		{"(1 + 2) / 4.0", 0.75},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5", "1.5"},
+		{"2.0", "2.0"},
+		{"0.5 * 4", "2.0"},
+		{"1e21", "1e+21"},
+	}
+
+	for _, tt := range tests {
+		if actual := testEval(tt.input).Inspect(); actual != tt.expected {
+			t.Errorf("wrong Inspect() for %q. expected=%q, got=%q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 < 1", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"1 == 2", false},
+		{"1 != 2", true},
+		{"true == true", true},
+		{"false == false", true},
+		{"true == false", false},
+		{"true != false", true},
+		{"1 < 2 == true", true},
+		{"1 > 2 == true", false},
+		{"(1 < 2) == true", true},
+		{"(1 > 2) == false", true},
+		{"1.5 < 2", true},
+		{"2 > 1.5", true},
+		{"1.0 == 1", true},
+		{"0.1 + 0.2 != 0.3", true},
+		{"1 <= 1", true},
+		{"1 <= 0", false},
+		{"1 >= 1", true},
+		{"0 >= 1", false},
+		{"1.5 >= 1", true},
+		{"true && true", true},
+		{"true && false", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"1 && 0", true},
+		{"let x = 5; x >= 0 && x < 10", true},
+		{"let x = 5; x < 0 || x > 10", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestStringLiteral(t *testing.T) {
+	input := `"Hello\tWorld!\n"`
+
+	evaluated := testEval(input)
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Hello\tWorld!\n" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestStringConcatenation(t *testing.T) {
+	input := `"Hello" + " " + "World!"`
+
+	evaluated := testEval(input)
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Hello World!" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestStringComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" + "b" == "ab"`, true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestShortCircuitEvaluation(t *testing.T) {
+	// the right operand would be an error if it were evaluated
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"false && unknown", false},
+		{"true || unknown", true},
+		{"1 > 2 && 1 / 0 == 0", false},
+		{"let n = 0; n == 0 || 10 / n > 1", true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFloatModulo(t *testing.T) {
+	testFloatObject(t, testEval("5.5 % 2"), 1.5)
+}
+
+func TestBangOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"!true", false},
+		{"!false", true},
+		{"!5", false},
+		{"!!true", true},
+		{"!!false", false},
+		{"!!5", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestIfElseExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if (true) { 10 }", 10},
+		{"if (false) { 10 }", nil},
+		{"if (1) { 10 }", 10},
+		{"if (1 < 2) { 10 }", 10},
+		{"if (1 > 2) { 10 }", nil},
+		{"if (1 > 2) { 10 } else { 20 }", 20},
+		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if (1 > 2) { 10 } else if (2 > 1) { 20 } else { 30 }", 20},
+		{"if (1 > 2) { 10 } else if (2 > 3) { 20 } else { 30 }", 30},
+		{"if (1 > 2) { 10 } else if (2 > 3) { 20 }", nil},
+		{"if (true) {}", nil},
+		{"if (true) { let y = 1 }", nil},
+		{"if (false) { 10 } else {}", nil},
+		{"let x = if (true) {}; x", nil},
+		{"let x = if (true) { let y = 1 }; x", nil},
+		{"[if (true) {}][0]", nil},
+		{"{1: if (true) {}}[1]", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestEmptyBranchInOperators(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"let x = if (true) {}; x + 1", "type mismatch: NULL + INTEGER"},
+		{"let x = if (true) { let y = 1 }; x + 1", "type mismatch: NULL + INTEGER"},
+		{"-if (true) {}", "unknown operator: -NULL"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("input %q: no error object returned. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("input %q: wrong error message. expected=%q, got=%q", tt.input, tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(result.Elements) != 3 {
+		t.Fatalf("array has wrong num of elements. got=%d", len(result.Elements))
+	}
+
+	testIntegerObject(t, result.Elements[0], 1)
+	testIntegerObject(t, result.Elements[1], 4)
+	testIntegerObject(t, result.Elements[2], 6)
+
+	if result.Inspect() != "[1, 4, 6]" {
+		t.Errorf("array.Inspect() wrong. got=%q", result.Inspect())
+	}
+}
+
+func TestArrayIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"[1, 2, 3][0]", 1},
+		{"[1, 2, 3][1]", 2},
+		{"[1, 2, 3][2]", 3},
+		{"let i = 0; [1][i];", 1},
+		{"[1, 2, 3][1 + 1];", 3},
+		{"let myArray = [1, 2, 3]; myArray[2];", 3},
+		{"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
+		{"let myArray = [1, 2, 3]; let i = myArray[0]; myArray[i]", 2},
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+		{"[[1, 2], [3, 4]][1][0]", 3},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestHashLiterals(t *testing.T) {
+	input := `let two = "two";
+	{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 / 2,
+		4: 4,
+		true: 5,
+		false: 6
+	}`
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		TRUE.HashKey():                             5,
+		FALSE.HashKey():                            6,
+	}
+
+	if len(result.Pairs) != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	}
+
+	for expectedKey, expectedValue := range expected {
+		pair, ok := result.Pairs[expectedKey]
+		if !ok {
+			t.Errorf("no pair for given key in Pairs")
+		}
+
+		testIntegerObject(t, pair.Value, expectedValue)
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+		{`{"a": 1, "a": 2}["a"]`, 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestFunctionObject(t *testing.T) {
+	input := "fn(x) { x + 2; };"
+
+	evaluated := testEval(input)
+	fn, ok := evaluated.(*object.Function)
+	if !ok {
+		t.Fatalf("object is not Function. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(fn.Parameters) != 1 {
+		t.Fatalf("function has wrong parameters. Parameters=%+v", fn.Parameters)
+	}
+
+	if fn.Parameters[0].String() != "x" {
+		t.Fatalf("parameter is not 'x'. got=%q", fn.Parameters[0])
+	}
+
+	expectedBody := "(x + 2)"
+
+	if fn.Body.String() != expectedBody {
+		t.Fatalf("body is not %q. got=%q", expectedBody, fn.Body.String())
+	}
+}
+
+func TestFunctionApplication(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let identity = fn(x) { x; }; identity(5);", 5},
+		{"let identity = fn(x) { return x; }; identity(5);", 5},
+		{"let double = fn(x) { x * 2; }; double(5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
+		{"let add = fn(a, b) { a + b }; add(1, 2)", 3},
+		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"fn(x) { x; }(5)", 5},
+		{"let f = fn() { return 1; 2 }; f() + 10", 11},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestClosures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{
+			`let adder = fn(x) { fn(y) { x + y } };
+			let addTwo = adder(2);
+			addTwo(3);`,
+			5,
+		},
+		{
+			// the closure keeps the x of its own call
+			`let adder = fn(x) { fn(y) { x + y } };
+			let addOne = adder(1);
+			let addTen = adder(10);
+			addOne(1) + addTen(1);`,
+			13,
+		},
+		{
+			// parameters shadow outer bindings without changing them
+			`let x = 10;
+			let f = fn(x) { x * 2 };
+			f(1) + x;`,
+			12,
+		},
+		{
+			`let applyTwice = fn(f, x) { f(f(x)) };
+			applyTwice(fn(x) { x * 3 }, 2);`,
+			18,
+		},
+		{
+			`let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };
+			fib(10);`,
+			55,
+		},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestEmptyFunctionBody(t *testing.T) {
+	testNullObject(t, testEval("fn() {}()"))
+}
+
+func TestErrorHandling(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"5 + true;", "type mismatch: INTEGER + BOOLEAN"},
+		{"5 + true; 5;", "type mismatch: INTEGER + BOOLEAN"},
+		{"-true", "unknown operator: -BOOLEAN"},
+		{"true + false;", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"5; true + false; 5", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"foobar", "identifier not found: foobar"},
+		{"if (10 > 1) { true + false; }", "unknown operator: BOOLEAN + BOOLEAN"},
+		{
+			`if (10 > 1) {
+				if (10 > 1) {
+					return true + false;
+				}
+
+				return 1;
+			}`,
+			"unknown operator: BOOLEAN + BOOLEAN",
+		},
+		{"10 / 0", "division by zero: 10 / 0"},
+		{"1.5 / 0", "division by zero: 1.5 / 0"},
+		{"10 % 0", "division by zero: 10 % 0"},
+		{"[1, 2, 3][3]", "index out of range: 3 (array length 3)"},
+		{"[1, 2, 3][-4]", "index out of range: -4 (array length 3)"},
+		{"[][0]", "index out of range: 0 (array length 0)"},
+		{`[1, 2, 3]["a"]`, "array index must be INTEGER, got STRING"},
+		{"1[0]", "index operator not supported: INTEGER"},
+		{"[1, 2 + true, 3]", "type mismatch: INTEGER + BOOLEAN"},
+		{`{"name": "Monkey"}[[1]]`, "unusable as hash key: ARRAY"},
+		{`{[1]: 2}`, "unusable as hash key: ARRAY"},
+		{`{"a": 1 + true}`, "type mismatch: INTEGER + BOOLEAN"},
+		{"5(1)", "not a function: INTEGER"},
+		{"fn(x, y) { x + y }(1)", "wrong number of arguments: want=2, got=1"},
+		{"let f = fn(x) { x + true }; f(1)", "type mismatch: INTEGER + BOOLEAN"},
+		{"let f = fn() { y }; let g = fn() { let y = 1; f() }; g()", "identifier not found: y"},
+		{"true && 1 + true", "type mismatch: INTEGER + BOOLEAN"},
+		{"1.5 + true", "type mismatch: FLOAT + BOOLEAN"},
+		{`"Hello" - "World"`, "unknown operator: STRING - STRING"},
+		{`"Hello" + 1`, "type mismatch: STRING + INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestErrorKindsAndPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 + true;", "TypeError: type mismatch: INTEGER + BOOLEAN at script.mk:1:3"},
+		{"let a = 1;\nlet b = a + true;", "TypeError: type mismatch: INTEGER + BOOLEAN at script.mk:2:11"},
+		{"-true", "TypeError: unknown operator: -BOOLEAN at script.mk:1:1"},
+		{"1 + foobar", "NameError: identifier not found: foobar at script.mk:1:5"},
+		{"[1, 2][5]", "IndexError: index out of range: 5 (array length 2) at script.mk:1:7"},
+		{"10 / (5 - 5)", "ZeroDivisionError: division by zero: 10 / 0 at script.mk:1:4"},
+		{"let f = fn(x) { x };\nf(1, 2)", "ArgumentError: wrong number of arguments: want=1, got=2 at script.mk:2:1"},
+		{"let x = 5; x(1)", "TypeError: not a function: INTEGER at script.mk:1:12"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalFile("script.mk", tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Inspect() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errObj.Inspect())
+		}
+	}
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	input := `let add = fn(a, b) {
+  a + b
+};
+let twice = fn(x) { add(x, true) };
+twice(1);
+fn() { twice(2) }();`
+
+	evaluated := testEvalFile("script.mk", input)
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	// the first error stops the program, so the anonymous function is never called
+	expected := `TypeError: type mismatch: INTEGER + BOOLEAN at script.mk:2:5
+  in add, called at script.mk:4:21
+  in twice, called at script.mk:5:1`
+	if errObj.Inspect() != expected {
+		t.Errorf("wrong stack trace. expected=\n%s\ngot=\n%s", expected, errObj.Inspect())
+	}
+}
+
+func TestMaximumRecursionDepth(t *testing.T) {
+	evaluated := testEval("let f = fn(n) { f(n + 1) };\nf(0);")
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Kind != object.RuntimeError || errObj.Message != "maximum recursion depth exceeded" {
+		t.Errorf("wrong error. got=%q", errObj.Error())
+	}
+	if errObj.Pos.Line != 1 || errObj.Pos.Column != 17 {
+		t.Errorf("wrong error position. got=%s", errObj.Pos)
+	}
+	if len(errObj.Stack) != maxCallDepth+1 {
+		t.Errorf("wrong number of stack frames. expected=%d, got=%d", maxCallDepth+1, len(errObj.Stack))
+	}
+
+	// recursion just below the limit is fine
+	input := fmt.Sprintf("let count = fn(n) { if (n == 0) { 0 } else { 1 + count(n - 1) } };\ncount(%d)", maxCallDepth-1)
+	testIntegerObject(t, testEval(input), int64(maxCallDepth-1))
+}
+
+func TestAnonymousFunctionStackFrame(t *testing.T) {
+	evaluated := testEval(`fn(x) { x + true }(1)`)
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	if len(errObj.Stack) != 1 {
+		t.Fatalf("wrong number of stack frames. got=%d", len(errObj.Stack))
+	}
+	if errObj.Stack[0].Function != "" {
+		t.Errorf("anonymous function has a name. got=%q", errObj.Stack[0].Function)
+	}
+	expected := "TypeError: type mismatch: INTEGER + BOOLEAN at 1:11\n  in <anonymous>, called at 1:19"
+	if errObj.Inspect() != expected {
+		t.Errorf("wrong error. expected=%q, got=%q", expected, errObj.Inspect())
+	}
+}
+
+func TestReturnStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"return 10;", 10},
+		{"return 10; 9;", 10},
+		{"return 2 * 5; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
+		{
+			`if (10 > 1) {
+				if (10 > 1) {
+					return 10;
+				}
+
+				return 1;
+			}`,
+			10,
+		},
+		// a return inside an if used as a value returns from the surrounding function
+		{"let x = if (true) { return 5 }; 10", 5},
+		{"fn() { let x = if (true) { return 5 }; 10 }()", 5},
+		{"fn() { return if (true) { return 5 }; 10 }()", 5},
+		{"fn() { len([if (true) { return 5 }]); 10 }()", 5},
+		{"fn() { -if (true) { return 5 }; 10 }()", 5},
+		{"fn() { 1 + if (true) { return 5 }; 10 }()", 5},
+		{"fn() { if (true) { return 5 } + 1; 10 }()", 5},
+		{"fn() { if (if (true) { return 5 }) { 1 }; 10 }()", 5},
+		{"fn() { true && if (true) { return 5 }; 10 }()", 5},
+		{"fn() { [1][if (true) { return 5 }]; 10 }()", 5},
+		{"fn() { {1: if (true) { return 5 }}; 10 }()", 5},
+		{"fn() { {if (true) { return 5 }: 1}; 10 }()", 5},
+		{"fn() { puts(if (true) { return 5 }); 10 }()", 5},
+		{"let x = if (true) { return 5 }; type(x)", 5},
+		{"let f = fn() { let x = if (true) { return 5 }; 10 }; f() + 1", 6},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let a = 5; a;", 5},
+		{"let a = 5 * 5; a;", 25},
+		{"let a = 5; let b = a; b;", 5},
+		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func testEval(input string) object.Object {
+	return testEvalFile("", input)
+}
+
+func testEvalFile(filename, input string) object.Object {
+	l := lexer.NewFile(filename, input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+
+	return Eval(program, env)
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%d, want=%d", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Errorf("object is not Boolean. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%t, want=%t", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func testNullObject(t *testing.T, obj object.Object) bool {
+	if obj != NULL {
+		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
+		return false
+	}
+	return true
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%g, want=%g", result.Value, expected)
+		return false
+	}
+
+	return true
+}
@@ -1,39 +1,155 @@
 package lexer
 
-import "monkeylang/token"
+import (
+	"fmt"
+	"monkeylang/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
 // Lexer - lexer for the monkeylanguage
 type Lexer struct {
 	input        string
 	position     int  // current position in input (points to current chat)
 	readPosition int  // current reading position in input
-	ch           byte // current char under examination
+	ch           rune // current char under examination
+	width        int  // width of the current char in bytes
+
+	filename string // name of the source file, used in token positions
+	line     int    // line of the current char
+	column   int    // column of the current char, counted in runes
+
+	mode       Mode
+	errors     []*Error
+	unfinished bool // the input ended inside a string or block comment
+}
+
+// Mode - set of flags controlling optional lexer behaviour
+type Mode uint
+
+const (
+	ScanComments Mode = 1 << iota // return comments as token.COMMENT tokens instead of skipping them
+)
+
+// Error - an error found while reading tokens (e.g: invalid UTF-8).
+// the offending input is returned as a token.ILLEGAL token at the same position
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + e.Msg
 }
 
 // New - returns a new lexer instance
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewFile("", input)
+}
+
+// NewFile - returns a new lexer instance for source code read from filename.
+// the file name is attached to the position of every token
+func NewFile(filename, input string) *Lexer {
+	l := &Lexer{input: input, filename: filename, line: 1}
 	l.readChar()
 	return l
 }
 
+// SetMode - change the mode of the lexer (e.g: lexer.ScanComments)
+func (l *Lexer) SetMode(mode Mode) {
+	l.mode = mode
+}
+
+// Errors - errors found in the input so far
+func (l *Lexer) Errors() []*Error {
+	return l.errors
+}
+
+// Unfinished - reports whether the input ended inside a string literal or a block comment,
+// i.e. more input could complete it (used by the REPL to ask for another line)
+func (l *Lexer) Unfinished() bool {
+	return l.unfinished
+}
+
+// addError - record an error at pos
+func (l *Lexer) addError(pos token.Position, msg string) {
+	l.errors = append(l.errors, &Error{Pos: pos, Msg: msg})
+}
+
 // readChar - give us the next characted and advance position in the input string.
-// In order to support full Unicode and UTF-8 (currently only ASCII) we need to change `l.ch` from
-// byte to rune, and chance the way next char is read
+// the input is decoded as UTF-8, one rune at a time. invalid bytes are read as
+// utf8.RuneError with a width of 1 (see invalidChar())
 func (l *Lexer) readChar() {
+	// once we are past the end of input, stay there
+	if l.position >= len(l.input) && l.readPosition > 0 {
+		return
+	}
+
+	// keep track of line and column of the char we are about to read
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+
 	if l.readPosition >= len(l.input) { // check to see if we have reached end of input
-		l.ch = 0 // set ch to 0 ~ ASCII code for 'NUL'
+		l.ch, l.width = 0, 1 // set ch to 0 ~ ASCII code for 'NUL'
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, l.width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition // position should point to the last read token
-	l.readPosition += 1         // increment readposition so we know what comes next
+	l.readPosition += l.width   // skip over all bytes of the rune so we know what comes next
 }
 
+// invalidChar - the current char is not valid UTF-8
+func (l *Lexer) invalidChar() bool {
+	return l.ch == utf8.RuneError && l.width == 1
+}
+
+// NextToken - returns the next token in the input, along with its start and end position.
+// comments are skipped, unless the lexer is in ScanComments mode
 func (l *Lexer) NextToken() token.Token {
+	for {
+		l.skipWhitespace()
+
+		pos := l.currentPosition()
+		var tok token.Token
+		if l.ch == '/' && (l.peekChar() == '/' || l.peekChar() == '*') {
+			tok = l.readComment()
+			if tok.Type == token.COMMENT && l.mode&ScanComments == 0 {
+				continue
+			}
+		} else {
+			tok = l.readToken()
+		}
+		tok.Pos = pos
+		tok.End = l.currentPosition()
+
+		return tok
+	}
+}
+
+// atEOF - we have read past the last char of the input
+func (l *Lexer) atEOF() bool {
+	return l.ch == 0 && l.position >= len(l.input)
+}
+
+// currentPosition - position of the char under examination
+func (l *Lexer) currentPosition() token.Position {
+	return token.Position{
+		Filename: l.filename,
+		Offset:   l.position,
+		Line:     l.line,
+		Column:   l.column,
+	}
+}
+
+// readToken - reads the token starting at the current char
+func (l *Lexer) readToken() token.Token {
 	var tok token.Token
 
-	l.skipWhitespace()
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
@@ -59,12 +175,50 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.SLASH, l.ch)
 	case '*':
 		tok = newToken(token.ASTERISK, l.ch)
+	case '%':
+		tok = newToken(token.PERCENT, l.ch)
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.LT_EQ, Literal: literal}
+		} else {
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.GT_EQ, Literal: literal}
+		} else {
+			tok = newToken(token.GT, l.ch)
+		}
+	case '&':
+		if l.peekChar() == '&' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.AND, Literal: literal}
+		} else {
+			l.addError(l.currentPosition(), "unexpected character '&', did you mean '&&'?")
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.OR, Literal: literal}
+		} else {
+			l.addError(l.currentPosition(), "unexpected character '|', did you mean '||'?")
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
@@ -77,19 +231,33 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
 		tok = newToken(token.RBRACE, l.ch)
+	case '[':
+		tok = newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = newToken(token.RBRACKET, l.ch)
+	case '"':
+		tok = l.readString()
 	case 0:
+		// a NUL byte in the input is not the end of it
+		if !l.atEOF() {
+			l.addError(l.currentPosition(), fmt.Sprintf("unexpected character %q", l.ch))
+			tok = newToken(token.ILLEGAL, l.ch)
+			break
+		}
 		tok.Literal = ""
 		tok.Type = token.EOF
 	default:
-		if isLetter(l.ch) {
+		if l.invalidChar() {
+			l.addError(l.currentPosition(), fmt.Sprintf("invalid UTF-8 encoding (byte %#x)", l.input[l.position]))
+			tok = token.Token{Type: token.ILLEGAL, Literal: l.input[l.position:l.readPosition]}
+		} else if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
 			return tok // early exit is necessary. Makes sure we dont call readChar() after switch again
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
-			return tok
+			return l.readNumber()
 		} else {
+			l.addError(l.currentPosition(), fmt.Sprintf("unexpected character %q", l.ch))
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
 	}
@@ -97,43 +265,317 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
-// readIdentifier - reads in an identifier and advance lexer's position
+// readIdentifier - reads in an identifier and advance lexer's position.
+// identifiers start with a letter, and may contain digits after that (e.g: x1)
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) { // iterate over all letters. used in default NextToken() case
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) { // iterate over all letters. used in default NextToken() case
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-// readNumber
-func (l *Lexer) readNumber() string {
+// readNumber - reads an integer (e.g: 42) or a float (e.g: 1.5, 2e10, 1.5e-3).
+// a float needs digits on both sides of the decimal point.
+// integers may also be written in hex (0xFF), octal (0o755) or binary (0b1010), and
+// underscores may separate digits in any number (e.g: 1_000_000, 0xFF_FF).
+// integers other than 0 must not start with a zero (010 is an error), floats may (010.5)
+func (l *Lexer) readNumber() token.Token {
 	position := l.position
-	for isDigit(l.ch) {
+	pos := l.currentPosition()
+	tokenType := token.TokenType(token.INT)
+
+	if l.ch == '0' {
+		if base, ok := basePrefixes[l.peekChar()]; ok {
+			return l.readBasedInteger(base)
+		}
+	}
+
+	l.readDigits()
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
 		l.readChar()
+		l.readDigits()
 	}
-	return l.input[position:l.position]
+
+	if l.ch == 'e' || l.ch == 'E' {
+		tokenType = token.FLOAT
+		pos := l.currentPosition()
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		if !isDigit(l.ch) {
+			l.addError(pos, "exponent has no digits")
+			return token.Token{Type: token.ILLEGAL, Literal: l.input[position:l.position]}
+		}
+		l.readDigits()
+	}
+
+	literal := l.input[position:l.position]
+	if i := invalidSeparator(literal, 0, isDigit); i >= 0 {
+		l.addError(offsetPosition(pos, i), "'_' must separate successive digits")
+		return token.Token{Type: token.ILLEGAL, Literal: literal}
+	}
+
+	// 010 would be read as octal by strconv, octal literals have to use the 0o prefix
+	if tokenType == token.INT && len(literal) > 1 && literal[0] == '0' {
+		l.addError(pos, fmt.Sprintf("invalid integer literal %s: leading zeros are not allowed (use 0o for octal)", literal))
+		return token.Token{Type: token.ILLEGAL, Literal: literal}
+	}
+
+	return token.Token{Type: tokenType, Literal: literal}
+}
+
+// readDigits - reads decimal digits and underscores
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
+		l.readChar()
+	}
+}
+
+// numberBase - digits allowed after a 0x, 0o or 0b prefix
+type numberBase struct {
+	name    string
+	isDigit func(rune) bool
+}
+
+var basePrefixes = map[rune]numberBase{
+	'x': {"hexadecimal", isHexDigit},
+	'X': {"hexadecimal", isHexDigit},
+	'o': {"octal", func(ch rune) bool { return '0' <= ch && ch <= '7' }},
+	'O': {"octal", func(ch rune) bool { return '0' <= ch && ch <= '7' }},
+	'b': {"binary", func(ch rune) bool { return ch == '0' || ch == '1' }},
+	'B': {"binary", func(ch rune) bool { return ch == '0' || ch == '1' }},
+}
+
+// readBasedInteger - reads a hex, octal or binary integer, starting at the leading 0.
+// letters and digits right after the literal are read as part of it, so 0b102 is
+// reported as an invalid binary literal instead of being split into 0b10 and 2
+func (l *Lexer) readBasedInteger(base numberBase) token.Token {
+	position := l.position
+	pos := l.currentPosition()
+
+	// skip the prefix
+	l.readChar()
+	l.readChar()
+
+	for isLetter(l.ch) || isDigit(l.ch) {
+		l.readChar()
+	}
+	literal := l.input[position:l.position]
+	illegal := token.Token{Type: token.ILLEGAL, Literal: literal}
+
+	digits := strings.Replace(literal[2:], "_", "", -1)
+	if digits == "" {
+		l.addError(pos, base.name+" literal has no digits")
+		return illegal
+	}
+
+	for i, ch := range literal {
+		if i >= 2 && ch != '_' && !base.isDigit(ch) {
+			l.addError(offsetPosition(pos, i), fmt.Sprintf("invalid digit %q in %s literal", ch, base.name))
+			return illegal
+		}
+	}
+
+	if i := invalidSeparator(literal, 2, base.isDigit); i >= 0 {
+		l.addError(offsetPosition(pos, i), "'_' must separate successive digits")
+		return illegal
+	}
+
+	return token.Token{Type: token.INT, Literal: literal}
+}
+
+// invalidSeparator - returns the index of the first underscore in literal that is not
+// placed between two digits, or -1 if there is none. prefixLen is the length of the
+// base prefix (0x, 0o, 0b), an underscore right after the prefix is allowed (e.g: 0x_FF)
+func invalidSeparator(literal string, prefixLen int, isDigit func(rune) bool) int {
+	for i := prefixLen; i < len(literal); i++ {
+		if literal[i] != '_' {
+			continue
+		}
+		prevOK := i == prefixLen && prefixLen > 0 || i > prefixLen && isDigit(rune(literal[i-1]))
+		nextOK := i+1 < len(literal) && isDigit(rune(literal[i+1]))
+		if !prevOK || !nextOK {
+			return i
+		}
+	}
+	return -1
+}
+
+// offsetPosition - position of the char n bytes after pos. only valid within a single line of ASCII chars
+func offsetPosition(pos token.Position, n int) token.Position {
+	pos.Offset += n
+	pos.Column += n
+	return pos
+}
+
+// readComment - reads a // line comment or a /* block comment */, starting at the first slash.
+// block comments may be nested, e.g: /* outer /* inner */ still a comment */
+// the literal of the token is the whole comment, including the delimiters
+func (l *Lexer) readComment() token.Token {
+	pos := l.currentPosition()
+	start := l.position
+
+	// line comment - everything up to the end of the line
+	if l.peekChar() == '/' {
+		for l.ch != '\n' && !l.atEOF() {
+			l.readChar()
+		}
+		literal := strings.TrimSuffix(l.input[start:l.position], "\r")
+		return token.Token{Type: token.COMMENT, Literal: literal}
+	}
+
+	// skip the opening /*
+	l.readChar()
+	l.readChar()
+
+	for depth := 1; depth > 0; {
+		switch {
+		case l.atEOF():
+			l.addError(pos, "unterminated block comment")
+			l.unfinished = true
+			return token.Token{Type: token.ILLEGAL, Literal: l.input[start:]}
+		case l.ch == '/' && l.peekChar() == '*':
+			depth++
+			l.readChar()
+		case l.ch == '*' && l.peekChar() == '/':
+			depth--
+			l.readChar()
+		}
+		l.readChar()
+	}
+
+	return token.Token{Type: token.COMMENT, Literal: l.input[start:l.position]}
+}
+
+// readString - reads a string literal, starting at the opening quote and stopping at the closing one.
+// the literal of the token is the string with all escape sequences replaced.
+// unterminated strings, bad escape sequences and invalid UTF-8 turn the whole string into a token.ILLEGAL
+func (l *Lexer) readString() token.Token {
+	start := l.currentPosition()
+	valid := true
+
+	var out strings.Builder
+	for {
+		l.readChar()
+
+		switch {
+		case l.ch == '"':
+			if !valid {
+				return token.Token{Type: token.ILLEGAL, Literal: l.input[start.Offset:l.readPosition]}
+			}
+			return token.Token{Type: token.STRING, Literal: out.String()}
+		case l.atEOF():
+			l.addError(start, "unterminated string")
+			l.unfinished = true
+			return token.Token{Type: token.ILLEGAL, Literal: l.input[start.Offset:l.position]}
+		case l.invalidChar():
+			l.addError(l.currentPosition(), fmt.Sprintf("invalid UTF-8 encoding (byte %#x)", l.input[l.position]))
+			valid = false
+		case l.ch == '\\':
+			if !l.readEscape(&out) {
+				valid = false
+			}
+		default:
+			out.WriteRune(l.ch)
+		}
+	}
+}
+
+// readEscape - reads an escape sequence inside a string, starting at the backslash.
+// supported: \n, \t, \r, \", \\ and \u{...} (a unicode code point in hex, e.g: \u{1F600})
+func (l *Lexer) readEscape(out *strings.Builder) bool {
+	pos := l.currentPosition()
+	l.readChar()
+
+	switch l.ch {
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case 'r':
+		out.WriteByte('\r')
+	case '"':
+		out.WriteByte('"')
+	case '\\':
+		out.WriteByte('\\')
+	case 'u':
+		return l.readUnicodeEscape(pos, out)
+	case 0:
+		if l.atEOF() {
+			// let readString() report the unterminated string
+			return true
+		}
+		l.addError(pos, fmt.Sprintf("unknown escape sequence \\ followed by %q", l.ch))
+		return false
+	default:
+		l.addError(pos, fmt.Sprintf("unknown escape sequence \\%c", l.ch))
+		return false
+	}
+
+	return true
+}
+
+// readUnicodeEscape - reads the {...} part of a \u{...} escape sequence
+func (l *Lexer) readUnicodeEscape(pos token.Position, out *strings.Builder) bool {
+	if l.peekChar() != '{' {
+		l.addError(pos, "invalid unicode escape, expected \\u{...}")
+		return false
+	}
+	l.readChar()
+
+	start := l.readPosition
+	for isHexDigit(l.peekChar()) {
+		l.readChar()
+	}
+	digits := l.input[start:l.readPosition]
+
+	if l.peekChar() != '}' || len(digits) == 0 || len(digits) > 6 {
+		l.addError(pos, "invalid unicode escape, expected \\u{...} with 1 to 6 hex digits")
+		return false
+	}
+	l.readChar()
+
+	code, _ := strconv.ParseUint(digits, 16, 32)
+	if !utf8.ValidRune(rune(code)) {
+		l.addError(pos, fmt.Sprintf("invalid unicode code point \\u{%s}", digits))
+		return false
+	}
+
+	out.WriteRune(rune(code))
+	return true
+}
+
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
 }
 
-func isDigit(ch byte) bool {
+// isDigit - number literals only use ASCII digits
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-// isLetter - definition of a letter within the context of monkeylang. underscores allow for snake case
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// isLetter - definition of a letter within the context of monkeylang. underscores allow for snake case.
+// any unicode letter is allowed (e.g: größe, 名前)
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
 
 // peekChar - we want to peek ahead in input and not traverse through the input
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	} else {
-		return l.input[l.readPosition]
+		ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+		return ch
 	}
 }
 
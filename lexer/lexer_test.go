@@ -14,7 +14,7 @@ let add = fn(x, y) {
 };
 
 let result = add(five, ten);
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 
 if (5 < 10) {
@@ -118,3 +118,430 @@ if (5 < 10) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := `let x = 10;
+  x == 5
+
+`
+
+	tests := []struct {
+		expectedLiteral string
+		expectedPos     token.Position
+		expectedEnd     token.Position
+	}{
+		{"let", token.Position{Filename: "test.mk", Offset: 0, Line: 1, Column: 1}, token.Position{Filename: "test.mk", Offset: 3, Line: 1, Column: 4}},
+		{"x", token.Position{Filename: "test.mk", Offset: 4, Line: 1, Column: 5}, token.Position{Filename: "test.mk", Offset: 5, Line: 1, Column: 6}},
+		{"=", token.Position{Filename: "test.mk", Offset: 6, Line: 1, Column: 7}, token.Position{Filename: "test.mk", Offset: 7, Line: 1, Column: 8}},
+		{"10", token.Position{Filename: "test.mk", Offset: 8, Line: 1, Column: 9}, token.Position{Filename: "test.mk", Offset: 10, Line: 1, Column: 11}},
+		{";", token.Position{Filename: "test.mk", Offset: 10, Line: 1, Column: 11}, token.Position{Filename: "test.mk", Offset: 11, Line: 1, Column: 12}},
+		{"x", token.Position{Filename: "test.mk", Offset: 14, Line: 2, Column: 3}, token.Position{Filename: "test.mk", Offset: 15, Line: 2, Column: 4}},
+		{"==", token.Position{Filename: "test.mk", Offset: 16, Line: 2, Column: 5}, token.Position{Filename: "test.mk", Offset: 18, Line: 2, Column: 7}},
+		{"5", token.Position{Filename: "test.mk", Offset: 19, Line: 2, Column: 8}, token.Position{Filename: "test.mk", Offset: 20, Line: 2, Column: 9}},
+		{"", token.Position{Filename: "test.mk", Offset: 22, Line: 4, Column: 1}, token.Position{Filename: "test.mk", Offset: 22, Line: 4, Column: 1}},
+		{"", token.Position{Filename: "test.mk", Offset: 22, Line: 4, Column: 1}, token.Position{Filename: "test.mk", Offset: 22, Line: 4, Column: 1}},
+	}
+
+	l := NewFile("test.mk", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos != tt.expectedPos {
+			t.Fatalf("tests[%d] - pos wrong. expected=%+v, got=%+v", i, tt.expectedPos, tok.Pos)
+		}
+		if tok.End != tt.expectedEnd {
+			t.Fatalf("tests[%d] - end wrong. expected=%+v, got=%+v", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := "let größe = 5;\nlet 名前 = größe2 + x_1;\n@"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedColumn  int
+	}{
+		{token.LET, "let", 1},
+		{token.IDENT, "größe", 5},
+		{token.ASSIGN, "=", 11},
+		{token.INT, "5", 13},
+		{token.SEMICOLON, ";", 14},
+		{token.LET, "let", 1},
+		{token.IDENT, "名前", 5},
+		{token.ASSIGN, "=", 8},
+		{token.IDENT, "größe2", 10},
+		{token.PLUS, "+", 17},
+		{token.IDENT, "x_1", 19},
+		{token.SEMICOLON, ";", 22},
+		{token.ILLEGAL, "@", 1},
+		{token.EOF, "", 2},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - column wrong. expected=%d, got=%d", i, tt.expectedColumn, tok.Pos.Column)
+		}
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	input := "let a\xff = 1"
+
+	l := New(input)
+
+	expected := []token.TokenType{token.LET, token.IDENT, token.ILLEGAL, token.ASSIGN, token.INT, token.EOF}
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt, tok.Type)
+		}
+	}
+
+	errors := l.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 lexer error, got=%d", len(errors))
+	}
+	if errors[0].Error() != "1:6: invalid UTF-8 encoding (byte 0xff)" {
+		t.Errorf("wrong error. got=%q", errors[0].Error())
+	}
+}
+
+func TestNULByte(t *testing.T) {
+	input := "puts(1);\x00 let"
+
+	l := New(input)
+
+	expected := []token.TokenType{token.IDENT, token.LPAREN, token.INT, token.RPAREN, token.SEMICOLON, token.ILLEGAL, token.LET, token.EOF}
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt, tok.Type)
+		}
+	}
+
+	errors := l.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 lexer error, got=%d", len(errors))
+	}
+	if errors[0].Error() != `1:9: unexpected character '\x00'` {
+		t.Errorf("wrong error. got=%q", errors[0].Error())
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedError   string
+	}{
+		{`"foobar"`, token.STRING, "foobar", ""},
+		{`"foo bar"`, token.STRING, "foo bar", ""},
+		{`""`, token.STRING, "", ""},
+		{`"a\nb\tc\r"`, token.STRING, "a\nb\tc\r", ""},
+		{`"say \"hi\" \\o/"`, token.STRING, `say "hi" \o/`, ""},
+		{`"\u{48}\u{e9}\u{1F600}"`, token.STRING, "Hé😀", ""},
+		{`"grüße"`, token.STRING, "grüße", ""},
+		{`"foo`, token.ILLEGAL, `"foo`, "1:1: unterminated string"},
+		{`"foo\`, token.ILLEGAL, `"foo\`, "1:1: unterminated string"},
+		{`"a\qb"`, token.ILLEGAL, `"a\qb"`, `1:3: unknown escape sequence \q`},
+		{`"\u48"`, token.ILLEGAL, `"\u48"`, `1:2: invalid unicode escape, expected \u{...}`},
+		{`"\u{}"`, token.ILLEGAL, `"\u{}"`, `1:2: invalid unicode escape, expected \u{...} with 1 to 6 hex digits`},
+		{`"\u{D800}"`, token.ILLEGAL, `"\u{D800}"`, `1:2: invalid unicode code point \u{D800}`},
+		{"\"a\xffb\"", token.ILLEGAL, "\"a\xffb\"", "1:3: invalid UTF-8 encoding (byte 0xff)"},
+		{"\"a\x00b\"", token.STRING, "a\x00b", ""},
+		{"\"a\\\x00b\"", token.ILLEGAL, "\"a\\\x00b\"", `1:3: unknown escape sequence \ followed by '\x00'`},
+	}
+
+	for i, tt := range tests {
+		input := tt.input
+		if tt.expectedError == "" {
+			input += ";"
+		}
+		l := New(input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		errors := l.Errors()
+		if tt.expectedError == "" {
+			if len(errors) != 0 {
+				t.Fatalf("tests[%d] - unexpected errors: %v", i, errors)
+			}
+			if next := l.NextToken(); next.Type != token.SEMICOLON {
+				t.Fatalf("tests[%d] - string not closed properly, next token=%q", i, next.Type)
+			}
+			continue
+		}
+		if len(errors) == 0 || errors[0].Error() != tt.expectedError {
+			t.Fatalf("tests[%d] - error wrong. expected=%q, got=%v", i, tt.expectedError, errors)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading comment
+let x = 5; // trailing comment
+/* block
+   comment */ let y = /* inline */ 10;
+/* outer /* nested */ still comment */ x / y;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.COMMENT, "// leading comment"},
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "// trailing comment"},
+		{token.COMMENT, "/* block\n   comment */"},
+		{token.LET, "let"},
+		{token.IDENT, "y"},
+		{token.ASSIGN, "="},
+		{token.COMMENT, "/* inline */"},
+		{token.INT, "10"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "/* outer /* nested */ still comment */"},
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.IDENT, "y"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	// comments are skipped by default
+	l := New(input)
+	for _, tt := range tests {
+		if tt.expectedType == token.COMMENT {
+			continue
+		}
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("expected=%q (%q), got=%q (%q)", tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+
+	l = New(input)
+	l.SetMode(ScanComments)
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	input := "let a = 1; /* outer /* inner */"
+
+	l := New(input)
+	for tok := l.NextToken(); tok.Type != token.ILLEGAL; tok = l.NextToken() {
+		if tok.Type == token.EOF {
+			t.Fatalf("expected ILLEGAL token for unterminated comment")
+		}
+	}
+
+	errors := l.Errors()
+	if len(errors) != 1 || errors[0].Error() != "1:12: unterminated block comment" {
+		t.Fatalf("wrong errors. got=%v", errors)
+	}
+
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF after unterminated comment. got=%q", tok.Type)
+	}
+}
+
+func TestUnfinished(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`let a = "abc`, true},
+		{`let a = "abc\"`, true},
+		{"/* comment", true},
+		{"/* outer /* inner */", true},
+		{`let a = "abc";`, false},
+		{`"\q"`, false},
+		{"/* comment */ 1", false},
+		{"1 & 2", false},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+
+		if l.Unfinished() != tt.expected {
+			t.Errorf("input %q: wrong Unfinished. expected=%t, got=%t", tt.input, tt.expected, l.Unfinished())
+		}
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedError   string
+	}{
+		{"42", token.INT, "42", ""},
+		{"1.5", token.FLOAT, "1.5", ""},
+		{"0.25", token.FLOAT, "0.25", ""},
+		{"2e10", token.FLOAT, "2e10", ""},
+		{"1.5e-3", token.FLOAT, "1.5e-3", ""},
+		{"6.02E+23", token.FLOAT, "6.02E+23", ""},
+		{"1e", token.ILLEGAL, "1e", "1:2: exponent has no digits"},
+		{"1.5e+", token.ILLEGAL, "1.5e+", "1:4: exponent has no digits"},
+		{"0xFF", token.INT, "0xFF", ""},
+		{"0XdeadBEEF", token.INT, "0XdeadBEEF", ""},
+		{"0o755", token.INT, "0o755", ""},
+		{"0", token.INT, "0", ""},
+		{"0.5", token.FLOAT, "0.5", ""},
+		{"010.5", token.FLOAT, "010.5", ""},
+		{"0e5", token.FLOAT, "0e5", ""},
+		{"010", token.ILLEGAL, "010", "1:1: invalid integer literal 010: leading zeros are not allowed (use 0o for octal)"},
+		{"09", token.ILLEGAL, "09", "1:1: invalid integer literal 09: leading zeros are not allowed (use 0o for octal)"},
+		{"00", token.ILLEGAL, "00", "1:1: invalid integer literal 00: leading zeros are not allowed (use 0o for octal)"},
+		{"0_1", token.ILLEGAL, "0_1", "1:1: invalid integer literal 0_1: leading zeros are not allowed (use 0o for octal)"},
+		{"0b1010", token.INT, "0b1010", ""},
+		{"1_000_000", token.INT, "1_000_000", ""},
+		{"0xFF_FF", token.INT, "0xFF_FF", ""},
+		{"0x_FF", token.INT, "0x_FF", ""},
+		{"1_000.5e1_0", token.FLOAT, "1_000.5e1_0", ""},
+		{"0x", token.ILLEGAL, "0x", "1:1: hexadecimal literal has no digits"},
+		{"0b_", token.ILLEGAL, "0b_", "1:1: binary literal has no digits"},
+		{"0b102", token.ILLEGAL, "0b102", "1:5: invalid digit '2' in binary literal"},
+		{"0o78", token.ILLEGAL, "0o78", "1:4: invalid digit '8' in octal literal"},
+		{"0xFG", token.ILLEGAL, "0xFG", "1:4: invalid digit 'G' in hexadecimal literal"},
+		{"1__0", token.ILLEGAL, "1__0", "1:2: '_' must separate successive digits"},
+		{"10_", token.ILLEGAL, "10_", "1:3: '_' must separate successive digits"},
+		{"1_.5", token.ILLEGAL, "1_.5", "1:2: '_' must separate successive digits"},
+		{"0x__1", token.ILLEGAL, "0x__1", "1:3: '_' must separate successive digits"},
+		{"0b1_", token.ILLEGAL, "0b1_", "1:4: '_' must separate successive digits"},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		errors := l.Errors()
+		if tt.expectedError == "" {
+			if len(errors) != 0 {
+				t.Fatalf("tests[%d] - unexpected errors: %v", i, errors)
+			}
+		} else if len(errors) == 0 || errors[0].Error() != tt.expectedError {
+			t.Fatalf("tests[%d] - error wrong. expected=%q, got=%v", i, tt.expectedError, errors)
+		}
+
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Fatalf("tests[%d] - expected EOF after number. got=%q (%q)", i, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestNumberFollowedByDot(t *testing.T) {
+	// a dot needs a digit after it to be part of a float
+	l := New("1.")
+
+	if tok := l.NextToken(); tok.Type != token.INT || tok.Literal != "1" {
+		t.Fatalf("expected INT 1, got=%q (%q)", tok.Type, tok.Literal)
+	}
+	if tok := l.NextToken(); tok.Type != token.ILLEGAL || tok.Literal != "." {
+		t.Fatalf("expected ILLEGAL ., got=%q (%q)", tok.Type, tok.Literal)
+	}
+}
+
+func TestComparisonAndLogicalOperators(t *testing.T) {
+	input := `a <= b >= c < d > e && f || g % h & |`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.LT, "<"},
+		{token.IDENT, "d"},
+		{token.GT, ">"},
+		{token.IDENT, "e"},
+		{token.AND, "&&"},
+		{token.IDENT, "f"},
+		{token.OR, "||"},
+		{token.IDENT, "g"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "h"},
+		{token.ILLEGAL, "&"},
+		{token.ILLEGAL, "|"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+	if len(l.Errors()) != 2 {
+		t.Fatalf("expected 2 errors for single & and |. got=%v", l.Errors())
+	}
+}
+
+func TestBrackets(t *testing.T) {
+	l := New(`[1, 2][0]; {"a": 1}`)
+
+	expected := []token.TokenType{
+		token.LBRACKET, token.INT, token.COMMA, token.INT, token.RBRACKET,
+		token.LBRACKET, token.INT, token.RBRACKET, token.SEMICOLON,
+		token.LBRACE, token.STRING, token.COLON, token.INT, token.RBRACE, token.EOF,
+	}
+	for i, tt := range expected {
+		if tok := l.NextToken(); tok.Type != tt {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt, tok.Type)
+		}
+	}
+}
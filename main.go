@@ -2,16 +2,145 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
+	"monkeylang/ast"
+	"monkeylang/evaluator"
+	"monkeylang/lexer"
+	"monkeylang/object"
+	"monkeylang/parser"
 	"monkeylang/repl"
+	"monkeylang/token"
 	"os"
-	"os/user"
 )
 
+// exit codes
+const (
+	exitOK    = 0
+	exitError = 1 // the script has errors (lexer, parser or runtime)
+	exitUsage = 2 // wrong command line, or the file can not be read
+)
+
+const usage = `usage: monkey <command> [arguments]
+
+commands:
+  run <file>     evaluate a script
+  repl           start the interactive REPL (default when no command is given)
+  tokens <file>  print the tokens of a script
+  ast <file>     print the parsed program
+  check <file>   parse a script and report errors without running it
+`
+
 func main() {
-	user, err := user.Current()
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run - execute the command in args and return the exit code.
+// results (and the output of puts) go to stdout, errors and usage go to stderr
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		repl.Start(stdin, stdout)
+		return exitOK
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "repl":
+		if len(args) != 0 {
+			fmt.Fprint(stderr, usage)
+			return exitUsage
+		}
+		repl.Start(stdin, stdout)
+		return exitOK
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+
+	commands := map[string]func(filename, source string, stdout, stderr io.Writer) int{
+		"run":    runFile,
+		"tokens": printTokens,
+		"ast":    printAST,
+		"check":  checkFile,
+	}
+	fn, ok := commands[command]
+	if !ok {
+		fmt.Fprintf(stderr, "monkey: unknown command %q\n\n%s", command, usage)
+		return exitUsage
+	}
+	if len(args) != 1 {
+		fmt.Fprintf(stderr, "monkey: %s expects exactly one file\n\n%s", command, usage)
+		return exitUsage
+	}
+
+	source, err := ioutil.ReadFile(args[0])
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(stderr, "monkey: %v\n", err)
+		return exitUsage
+	}
+	return fn(args[0], string(source), stdout, stderr)
+}
+
+// parse - parse source, reporting parse errors to stderr. the program is nil if there are errors
+func parse(filename, source string, stderr io.Writer) *ast.Program {
+	p := parser.New(lexer.NewFile(filename, source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		fmt.Fprint(stderr, parser.RenderErrors(source, p.Errors()))
+		return nil
+	}
+	return program
+}
+
+// runFile - evaluate a script, the output of puts goes to stdout.
+// runtime errors are reported with their stack trace
+func runFile(filename, source string, stdout, stderr io.Writer) int {
+	program := parse(filename, source, stderr)
+	if program == nil {
+		return exitError
+	}
+
+	env := object.NewEnvironment()
+	env.SetOutput(stdout)
+	evaluated := evaluator.Eval(program, env)
+	if err, ok := evaluated.(*object.Error); ok {
+		fmt.Fprintln(stderr, err.Inspect())
+		return exitError
+	}
+	return exitOK
+}
+
+// printTokens - print every token the lexer returns until EOF, followed by the lexer errors
+func printTokens(filename, source string, stdout, stderr io.Writer) int {
+	l := lexer.NewFile(filename, source)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(stdout, "%+v\n", tok)
+	}
+
+	for _, err := range l.Errors() {
+		fmt.Fprintln(stderr, err.Error())
+	}
+	if len(l.Errors()) != 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// printAST - print the parsed program
+func printAST(filename, source string, stdout, stderr io.Writer) int {
+	program := parse(filename, source, stderr)
+	if program == nil {
+		return exitError
+	}
+
+	fmt.Fprintln(stdout, program.String())
+	return exitOK
+}
+
+// checkFile - parse a script without running it. prints nothing if there are no errors
+func checkFile(filename, source string, stdout, stderr io.Writer) int {
+	if parse(filename, source, stderr) == nil {
+		return exitError
 	}
-	fmt.Printf("Hello %s! This is EthereumOS!\n", user.Username)
-	repl.Start(os.Stdin, os.Stdout)
+	return exitOK
 }
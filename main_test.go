@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeScript(t *testing.T, source string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.mk")
+	if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCommands(t *testing.T) {
+	valid := writeScript(t, "let x = 1 + 2;\nx * 3;\n")
+	parseError := writeScript(t, "let x 5;\n")
+	runtimeError := writeScript(t, "let f = fn() { 1 + true };\nf();\n")
+	output := writeScript(t, "puts(\"hello\", 1 + 2);\n")
+
+	tests := []struct {
+		args           []string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string // only checked for being contained in stderr
+	}{
+		{[]string{"run", valid}, exitOK, "", ""},
+		{[]string{"run", output}, exitOK, "hello\n3\n", ""},
+		{[]string{"run", parseError}, exitError, "", "error[P001]: expected next token to be =, got INT instead"},
+		{[]string{"run", runtimeError}, exitError, "", "TypeError: type mismatch: INTEGER + BOOLEAN at " + runtimeError + ":1:18\n  in f, called at " + runtimeError + ":2:1\n"},
+		{[]string{"check", valid}, exitOK, "", ""},
+		{[]string{"check", parseError}, exitError, "", parseError + ":1:7: error[P001]"},
+		{[]string{"check", runtimeError}, exitOK, "", ""},
+		{[]string{"ast", valid}, exitOK, "let x = (1 + 2);(x * 3)\n", ""},
+		{[]string{"ast", parseError}, exitError, "", "error[P001]"},
+		{[]string{"run"}, exitUsage, "", "run expects exactly one file"},
+		{[]string{"run", valid, valid}, exitUsage, "", "run expects exactly one file"},
+		{[]string{"run", filepath.Join(t.TempDir(), "missing.mk")}, exitUsage, "", "no such file or directory"},
+		{[]string{"compile", valid}, exitUsage, "", `unknown command "compile"`},
+		{[]string{"help"}, exitOK, usage, ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(tt.args, strings.NewReader(""), &stdout, &stderr)
+
+		if code != tt.expectedCode {
+			t.Errorf("%v: wrong exit code. expected=%d, got=%d (stderr=%q)", tt.args, tt.expectedCode, code, stderr.String())
+		}
+		if stdout.String() != tt.expectedStdout {
+			t.Errorf("%v: wrong stdout. expected=%q, got=%q", tt.args, tt.expectedStdout, stdout.String())
+		}
+		if tt.expectedStderr == "" && stderr.Len() != 0 {
+			t.Errorf("%v: unexpected stderr. got=%q", tt.args, stderr.String())
+		}
+		if !strings.Contains(stderr.String(), tt.expectedStderr) {
+			t.Errorf("%v: wrong stderr. expected to contain %q, got=%q", tt.args, tt.expectedStderr, stderr.String())
+		}
+	}
+}
+
+func TestTokensCommand(t *testing.T) {
+	path := writeScript(t, "let x;")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"tokens", path}, strings.NewReader(""), &stdout, &stderr); code != exitOK {
+		t.Fatalf("wrong exit code. got=%d (stderr=%q)", code, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("wrong number of tokens. expected=3, got=%d (%q)", len(lines), stdout.String())
+	}
+	if !strings.HasPrefix(lines[0], "{Type:LET Literal:let") {
+		t.Errorf("wrong first token. got=%q", lines[0])
+	}
+
+	// tokens are still printed after a lexer error, the error goes to stderr
+	illegal := writeScript(t, "1 & 2")
+	stdout.Reset()
+	if code := run([]string{"tokens", illegal}, strings.NewReader(""), &stdout, &stderr); code != exitError {
+		t.Fatalf("wrong exit code. got=%d", code)
+	}
+	if !strings.Contains(stdout.String(), "{Type:ILLEGAL Literal:&") {
+		t.Errorf("illegal token is not printed. got=%q", stdout.String())
+	}
+	expected := illegal + ":1:3: unexpected character '&', did you mean '&&'?\n"
+	if stderr.String() != expected {
+		t.Errorf("wrong stderr. expected=%q, got=%q", expected, stderr.String())
+	}
+}
+
+func TestReplCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"repl"}, strings.NewReader("1 + 2\n"), &stdout, &stderr); code != exitOK {
+		t.Fatalf("wrong exit code. got=%d", code)
+	}
+	if !strings.Contains(stdout.String(), "3\n") {
+		t.Errorf("repl did not evaluate input. got=%q", stdout.String())
+	}
+}
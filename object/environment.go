@@ -0,0 +1,94 @@
+package object
+
+import (
+	"io"
+	"os"
+	"sort"
+)
+
+// Environment - keeps track of values bound to identifiers (e.g: via let statements).
+// environments are chained: every function call gets its own environment, enclosed
+// by the environment the function was defined in (its outer environment)
+type Environment struct {
+	store map[string]Object
+	outer *Environment
+	depth int       // number of function calls this environment is nested in, see CallDepth
+	out   io.Writer // output of builtins like puts, see Output
+}
+
+// NewEnvironment - create a new, empty environment
+func NewEnvironment() *Environment {
+	s := make(map[string]Object)
+	return &Environment{store: s, outer: nil}
+}
+
+// NewEnclosedEnvironment - create a new, empty environment that extends outer
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	env := NewEnvironment()
+	env.outer = outer
+	return env
+}
+
+// NewCallEnvironment - create the environment of a function call. it extends outer (the
+// environment the function was defined in) and is one call deeper than caller
+func NewCallEnvironment(outer, caller *Environment) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.depth = caller.depth + 1
+	return env
+}
+
+// CallDepth - number of function calls on the way from the top level to this environment
+func (e *Environment) CallDepth() int {
+	return e.depth
+}
+
+// SetOutput - send the output of builtins like puts to w. environments enclosed by
+// this one write to w as well, unless they set their own output
+func (e *Environment) SetOutput(w io.Writer) {
+	e.out = w
+}
+
+// Output - where builtins called in this environment write to. the output is
+// inherited from the outer environments, os.Stdout if none of them set one
+func (e *Environment) Output() io.Writer {
+	for env := e; env != nil; env = env.outer {
+		if env.out != nil {
+			return env.out
+		}
+	}
+	return os.Stdout
+}
+
+// Get - retrieve the value bound to name. names that are not bound in this
+// environment are looked up in the outer ones
+func (e *Environment) Get(name string) (Object, bool) {
+	obj, ok := e.store[name]
+	if !ok && e.outer != nil {
+		obj, ok = e.outer.Get(name)
+	}
+	return obj, ok
+}
+
+// Set - bind val to name. the binding is always created in this environment,
+// shadowing bindings of the same name in the outer ones
+func (e *Environment) Set(name string, val Object) Object {
+	e.store[name] = val
+	return val
+}
+
+// Names - sorted names of all bindings visible from this environment, including
+// the ones of the outer environments
+func (e *Environment) Names() []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for env := e; env != nil; env = env.outer {
+		for name := range env.store {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
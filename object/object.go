@@ -0,0 +1,293 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"monkeylang/ast"
+	"monkeylang/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ObjectType - string representation of an object's type (e.g: INTEGER, BOOLEAN)
+type ObjectType string
+
+const (
+	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
+	STRING_OBJ       = "STRING"
+	BOOLEAN_OBJ      = "BOOLEAN"
+	NULL_OBJ         = "NULL"
+	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	ERROR_OBJ        = "ERROR"
+	ARRAY_OBJ        = "ARRAY"
+	HASH_OBJ         = "HASH"
+	FUNCTION_OBJ     = "FUNCTION"
+	BUILTIN_OBJ      = "BUILTIN"
+)
+
+// Object - every value produced by the evaluator implements this interface
+type Object interface {
+	Type() ObjectType
+	Inspect() string // print the value (used by the REPL)
+}
+
+// HashKey - key of a pair in a hash object. objects with the same type and
+// value produce equal hash keys, even if they are different instances.
+// strings are keyed on their content (Text), not a hash of it, so different strings never share a key
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+	Text  string
+}
+
+// Hashable - objects that can be used as keys of a hash (integers, booleans and strings)
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+// Integer - wraps a go int64
+type Integer struct {
+	Value int64
+}
+
+func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
+func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
+func (i *Integer) HashKey() HashKey { return HashKey{Type: i.Type(), Value: uint64(i.Value)} }
+
+// Float - wraps a go float64
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// Inspect - always prints a decimal point or an exponent, so floats are not mistaken for integers
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
+// String - wraps a go string
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+
+func (s *String) HashKey() HashKey { return HashKey{Type: s.Type(), Text: s.Value} }
+
+// Boolean - wraps a go bool
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
+func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+// Null - represents the absence of a value
+type Null struct{}
+
+func (n *Null) Type() ObjectType { return NULL_OBJ }
+func (n *Null) Inspect() string  { return "null" }
+
+// ReturnValue - wraps the value of a return statement, so the evaluator
+// knows to stop evaluating the remaining statements
+type ReturnValue struct {
+	Value Object
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+
+// ErrorKind - the category of a runtime error (e.g: TypeError, NameError)
+type ErrorKind string
+
+const (
+	TypeError         ErrorKind = "TypeError"         // operands or arguments of the wrong type
+	NameError         ErrorKind = "NameError"         // identifier is not bound
+	IndexError        ErrorKind = "IndexError"        // index out of range
+	ZeroDivisionError ErrorKind = "ZeroDivisionError" // integer division or modulo by zero
+	ArgumentError     ErrorKind = "ArgumentError"     // wrong number of arguments
+	RuntimeError      ErrorKind = "RuntimeError"      // anything else
+)
+
+// StackFrame - a function call the error propagated through.
+// Pos is the position of the call site
+type StackFrame struct {
+	Function string // empty for anonymous functions
+	Pos      token.Position
+}
+
+// Error - produced when evaluation fails (e.g: unknown operators, type mismatches).
+// Pos is the position of the expression that failed, Stack holds the function
+// calls the error propagated through, innermost first
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Pos     token.Position
+	Stack   []StackFrame
+}
+
+// NewError - create an error without position, the evaluator fills it in
+func NewError(kind ErrorKind, format string, a ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
+}
+
+func (e *Error) Type() ObjectType { return ERROR_OBJ }
+
+// Error - formats the error as Kind: message at file:line:column
+func (e *Error) Error() string {
+	s := string(e.Kind) + ": " + e.Message
+	if e.Pos.IsValid() {
+		s += " at " + e.Pos.String()
+	}
+	return s
+}
+
+// maxInspectFrames - longer stack traces (e.g: of endless recursion) only print
+// their innermost and outermost frames
+const maxInspectFrames = 20
+
+// Inspect - prints the error followed by its stack trace, e.g:
+//
+//	TypeError: unknown operator: INTEGER + BOOLEAN at script.mk:2:14
+//	  in add, called at script.mk:4:1
+func (e *Error) Inspect() string {
+	var out bytes.Buffer
+
+	out.WriteString(e.Error())
+	for i, frame := range e.Stack {
+		if len(e.Stack) > maxInspectFrames && i >= maxInspectFrames/2 && i < len(e.Stack)-maxInspectFrames/2 {
+			if i == maxInspectFrames/2 {
+				out.WriteString(fmt.Sprintf("\n  ... %d more calls", len(e.Stack)-maxInspectFrames))
+			}
+			continue
+		}
+		name := frame.Function
+		if name == "" {
+			name = "<anonymous>"
+		}
+		out.WriteString(fmt.Sprintf("\n  in %s, called at %s", name, frame.Pos))
+	}
+
+	return out.String()
+}
+
+// Array - ordered list of objects
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+
+// Inspect - prints the elements in array literal syntax, e.g: [1, 2, 3]
+func (a *Array) Inspect() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, e := range a.Elements {
+		elements = append(elements, e.Inspect())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// HashPair - the original key object is kept next to the value, so a hash can be printed
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash - maps hashable objects to values
+type Hash struct {
+	Pairs map[HashKey]HashPair
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+
+// Inspect - prints the pairs in hash literal syntax, sorted by key so the output is stable
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", inspectKey(pair.Key), pair.Value.Inspect()))
+	}
+	sort.Strings(pairs)
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// inspectKey - quote string keys, so {"1": 1} and {1: 1} can be told apart
+func inspectKey(key Object) string {
+	if s, ok := key.(*String); ok {
+		return strconv.Quote(s.Value)
+	}
+	return key.Inspect()
+}
+
+// Function - a function value. Env is the environment the function was defined in,
+// which makes functions closures: they can access the bindings of their defining scope
+type Function struct {
+	Name       string // name of the first let statement the function was bound by, empty if anonymous
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+
+// Inspect - prints the function in function literal syntax
+func (f *Function) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range f.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("fn(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(f.Body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
+
+// BuiltinFunction - signature of functions implemented in go. env is the environment
+// of the call (e.g: for its Output). errors are reported by returning an *Error (see NewError)
+type BuiltinFunction func(env *Environment, args ...Object) Object
+
+// Builtin - a function implemented in go (e.g: len, puts)
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string  { return "builtin function " + b.Name }
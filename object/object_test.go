@@ -0,0 +1,167 @@
+package object
+
+import (
+	"bytes"
+	"monkeylang/token"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStringHashKey(t *testing.T) {
+	hello1 := &String{Value: "Hello World"}
+	hello2 := &String{Value: "Hello World"}
+	diff1 := &String{Value: "My name is johnny"}
+	diff2 := &String{Value: "My name is johnny"}
+
+	if hello1.HashKey() != hello2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if diff1.HashKey() != diff2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if hello1.HashKey() == diff1.HashKey() {
+		t.Errorf("strings with different content have same hash keys")
+	}
+
+	// a hash of the content could collide, the content itself can not
+	if key := hello1.HashKey(); key.Text != hello1.Value {
+		t.Errorf("string hash key is not its content. got=%+v", key)
+	}
+}
+
+func TestHashKeyTypes(t *testing.T) {
+	// same underlying value, different types
+	one := &Integer{Value: 1}
+	yes := &Boolean{Value: true}
+
+	if one.HashKey() == yes.HashKey() {
+		t.Errorf("integer 1 and true have the same hash key")
+	}
+
+	if (&Integer{Value: 1}).HashKey() != one.HashKey() {
+		t.Errorf("integers with same value have different hash keys")
+	}
+
+	if (&Boolean{Value: true}).HashKey() != yes.HashKey() {
+		t.Errorf("booleans with same value have different hash keys")
+	}
+}
+
+func TestHashInspect(t *testing.T) {
+	hash := &Hash{Pairs: map[HashKey]HashPair{}}
+	for _, key := range []Hashable{&String{Value: "b"}, &Integer{Value: 1}, &String{Value: "a"}} {
+		hash.Pairs[key.HashKey()] = HashPair{Key: key, Value: &Boolean{Value: true}}
+	}
+
+	expected := `{"a": true, "b": true, 1: true}`
+	if hash.Inspect() != expected {
+		t.Errorf("hash.Inspect() wrong. expected=%q, got=%q", expected, hash.Inspect())
+	}
+}
+
+func TestEnclosedEnvironment(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("a", &Integer{Value: 1})
+	outer.Set("b", &Integer{Value: 2})
+
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("b", &Integer{Value: 3})
+
+	tests := []struct {
+		env      *Environment
+		name     string
+		expected int64
+	}{
+		{inner, "a", 1},
+		{inner, "b", 3},
+		{outer, "b", 2},
+	}
+
+	for _, tt := range tests {
+		obj, ok := tt.env.Get(tt.name)
+		if !ok {
+			t.Fatalf("%s not found", tt.name)
+		}
+		if obj.(*Integer).Value != tt.expected {
+			t.Errorf("%s has wrong value. expected=%d, got=%d", tt.name, tt.expected, obj.(*Integer).Value)
+		}
+	}
+
+	if _, ok := outer.Get("c"); ok {
+		t.Errorf("unbound name c found")
+	}
+}
+
+func TestErrorInspect(t *testing.T) {
+	err := NewError(TypeError, "unknown operator: %s + %s", INTEGER_OBJ, BOOLEAN_OBJ)
+	if err.Inspect() != "TypeError: unknown operator: INTEGER + BOOLEAN" {
+		t.Errorf("error without position has wrong Inspect. got=%q", err.Inspect())
+	}
+
+	err.Pos = token.Position{Filename: "script.mk", Line: 3, Column: 7}
+	err.Stack = []StackFrame{
+		{Function: "add", Pos: token.Position{Filename: "script.mk", Line: 5, Column: 1}},
+		{Pos: token.Position{Filename: "script.mk", Line: 6, Column: 3}},
+	}
+	expected := "TypeError: unknown operator: INTEGER + BOOLEAN at script.mk:3:7\n" +
+		"  in add, called at script.mk:5:1\n" +
+		"  in <anonymous>, called at script.mk:6:3"
+	if err.Inspect() != expected {
+		t.Errorf("wrong Inspect. expected=%q, got=%q", expected, err.Inspect())
+	}
+}
+
+func TestErrorInspectLongStack(t *testing.T) {
+	err := NewError(RuntimeError, "maximum recursion depth exceeded")
+	for i := 1; i <= 100; i++ {
+		err.Stack = append(err.Stack, StackFrame{Function: "f", Pos: token.Position{Line: i, Column: 1}})
+	}
+
+	lines := strings.Split(err.Inspect(), "\n")
+	if len(lines) != maxInspectFrames+2 {
+		t.Fatalf("wrong number of lines. expected=%d, got=%d", maxInspectFrames+2, len(lines))
+	}
+	if lines[10] != "  in f, called at 10:1" || lines[11] != "  ... 80 more calls" || lines[12] != "  in f, called at 91:1" {
+		t.Errorf("wrong collapsed frames. got=%q", lines[10:13])
+	}
+}
+
+func TestEnvironmentOutput(t *testing.T) {
+	outer := NewEnvironment()
+	if outer.Output() != os.Stdout {
+		t.Errorf("default output is not os.Stdout. got=%v", outer.Output())
+	}
+
+	var out, own bytes.Buffer
+	outer.SetOutput(&out)
+	inner := NewCallEnvironment(NewEnclosedEnvironment(outer), outer)
+	if inner.Output() != &out {
+		t.Errorf("enclosed environment does not inherit the output")
+	}
+
+	inner.SetOutput(&own)
+	if inner.Output() != &own || outer.Output() != &out {
+		t.Errorf("output set on the enclosed environment changed the outer one")
+	}
+}
+
+func TestEnvironmentNames(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("b", &Integer{Value: 1})
+	outer.Set("a", &Integer{Value: 2})
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("c", &Integer{Value: 3})
+	inner.Set("a", &Integer{Value: 4})
+
+	names := inner.Names()
+	expected := []string{"a", "b", "c"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("wrong names. expected=%v, got=%v", expected, names)
+	}
+	if len(NewEnvironment().Names()) != 0 {
+		t.Errorf("empty environment has names")
+	}
+}
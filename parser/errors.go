@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"monkeylang/token"
+	"strings"
+)
+
+// ErrorCode - machine readable identifier for the kind of a parse error
+type ErrorCode string
+
+const (
+	ErrUnexpectedToken   ErrorCode = "P001" // the next token is not the one the grammar requires
+	ErrNoPrefixParseFn   ErrorCode = "P002" // a token can not start an expression
+	ErrInvalidInteger    ErrorCode = "P003" // integer literal does not fit into an int64
+	ErrMissingExpression ErrorCode = "P004" // e.g: 'let x = ;'
+	ErrUnclosedBlock     ErrorCode = "P005" // reached EOF before the closing brace
+	ErrIllegalToken      ErrorCode = "P006" // the lexer could not read a token (e.g: invalid UTF-8)
+	ErrInvalidFloat      ErrorCode = "P007" // float literal is out of range
+)
+
+// ParseError - an error found while parsing.
+// Pos and End are the start and end of the offending token.
+// Expected is only set if the parser was looking for a specific token
+type ParseError struct {
+	Pos      token.Position
+	End      token.Position
+	Code     ErrorCode
+	Expected token.TokenType
+	Got      token.TokenType
+	Msg      string
+}
+
+// Error - formats the error as file:line:column: message
+func (e *ParseError) Error() string {
+	if e.Pos.IsValid() {
+		return e.Pos.String() + ": " + e.Msg
+	}
+	return e.Msg
+}
+
+// Render - formats the error followed by the offending source line and a caret
+// underline below the offending token, e.g:
+//
+//	script.mk:1:7: error[P001]: expected next token to be =, got INT instead
+//	let x 5;
+//	      ^
+func (e *ParseError) Render(source string) string {
+	var out bytes.Buffer
+
+	out.WriteString(fmt.Sprintf("%s: error[%s]: %s\n", e.Pos, e.Code, e.Msg))
+
+	if !e.Pos.IsValid() {
+		return out.String()
+	}
+
+	lines := strings.Split(source, "\n")
+	if e.Pos.Line > len(lines) {
+		return out.String()
+	}
+	line := strings.TrimRight(lines[e.Pos.Line-1], "\r")
+
+	out.WriteString(line)
+	out.WriteString("\n")
+
+	// columns are counted in runes. keep tabs in the padding, so the caret lines up with the source line
+	column := 1
+	for _, ch := range line {
+		if column >= e.Pos.Column {
+			break
+		}
+		if ch == '\t' {
+			out.WriteByte('\t')
+		} else {
+			out.WriteByte(' ')
+		}
+		column++
+	}
+
+	// underline the whole token, as long as it ends on the same line
+	width := 1
+	if e.End.Line == e.Pos.Line && e.End.Column-e.Pos.Column > 1 {
+		width = e.End.Column - e.Pos.Column
+	}
+	out.WriteString(strings.Repeat("^", width))
+	out.WriteString("\n")
+
+	return out.String()
+}
+
+// RenderErrors - render every error against the same source code
+func RenderErrors(source string, errors []*ParseError) string {
+	var out bytes.Buffer
+
+	for _, e := range errors {
+		out.WriteString(e.Render(source))
+	}
+
+	return out.String()
+}
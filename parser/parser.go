@@ -12,12 +12,15 @@ const (
 	// use of iota gives the following constants incrementing values in ints
 	_ int = iota // 0
 	LOWEST       // 1
+	LOGICAL_OR   // ||
+	LOGICAL_AND  // &&
 	EQUALS       // ==
 	LESSGREATER  // > or <
 	SUM          // +
 	PRODUCT      // *
 	PREFIX       // -X or !X
 	CALL         // myFunction(X)
+	INDEX        // array[index]
 )
 
 // precedences table
@@ -28,10 +31,17 @@ var precedences = map[token.TokenType]int{
 	token.NOT_EQ: EQUALS,
 	token.LT: LESSGREATER,
 	token.GT: LESSGREATER,
+	token.LT_EQ: LESSGREATER,
+	token.GT_EQ: LESSGREATER,
 	token.PLUS: SUM,
 	token.MINUS: SUM,
 	token.SLASH: PRODUCT,
 	token.ASTERISK: PRODUCT,
+	token.PERCENT: PRODUCT,
+	token.AND: LOGICAL_AND,
+	token.OR: LOGICAL_OR,
+	token.LPAREN: CALL,
+	token.LBRACKET: INDEX,
 }
 
 // defined parser types with return type enforced
@@ -45,13 +55,17 @@ type (
 // curToken & peekToken - pointers similar to position
 // and readPosition on the lexer
 // prefixParseFns and infixParseFns - mapping of helper parsers
+// depth and panicking - used to recover from syntax errors (see synchronize())
 type Parser struct {
 	l      *lexer.Lexer
-	errors []string
+	errors []*ParseError
 
 	curToken  token.Token
 	peekToken token.Token
 
+	depth     int  // how many braces curToken is nested in
+	panicking bool // set after a syntax error, until the parser synchronized at the end of the statement
+
 	prefixParseFns map[token.TokenType]prefixParseFn // use curToken.Type to check if a prefix or infix parsing function exists
 	infixParseFns  map[token.TokenType]infixParseFn 
 }
@@ -84,14 +98,14 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addError(p.curToken, ErrNoPrefixParseFn, "", msg)
 }
 
 // New - create a new parser
 // takes in lexer as an argument
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{l: l,
-		errors: []string{},
+		errors: []*ParseError{},
 	}
 
 	// intitialize prefixparse map and register identifier parser
@@ -100,10 +114,18 @@ func New(l *lexer.Lexer) *Parser {
 	// initialize prefixParse functions in the mapping
 	p.registerPrefix(token.IDENT, p.parseIdentifier) // identifier tokens (token.IDENT)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral) // need tp register a prefix parser for token.INT tokens
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression) // !
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression) // -
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
+	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
+	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 
 	// infix parsing!
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -115,6 +137,13 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
 	// read two tokens - this ensures we've populated curToken and peekToken
 	p.nextToken()
@@ -123,7 +152,7 @@ func New(l *lexer.Lexer) *Parser {
 }
 
 // Errors - return errors in the parser
-func (p *Parser) Errors() []string {
+func (p *Parser) Errors() []*ParseError {
 	return p.errors
 }
 
@@ -159,24 +188,63 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+// parseIllegal - report tokens the lexer could not read. the lexer knows what went wrong
+// (and where exactly, e.g: a bad escape sequence inside a string), so use its first error within the token
+func (p *Parser) parseIllegal() ast.Expression {
+	tok := p.curToken
+	msg := fmt.Sprintf("illegal token %q", tok.Literal)
+
+	for _, err := range p.l.Errors() {
+		if err.Pos.Offset >= tok.Pos.Offset && err.Pos.Offset < tok.End.Offset {
+			msg = err.Msg
+			if err.Pos != tok.Pos {
+				tok.Pos, tok.End = err.Pos, err.Pos
+			}
+			break
+		}
+	}
+
+	p.addError(tok, ErrIllegalToken, "", msg)
+	return nil
+}
+
 // parseIdentifier - retrieve the identifier in ast expression format
 func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
 // nextToken - traverse to next token, adjust current and peek token references
+// also keeps track of how deep curToken is nested in braces.
+// comments (returned by lexers in lexer.ScanComments mode) are not part of the AST, skip them
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+	for p.peekToken.Type == token.COMMENT {
+		p.peekToken = p.l.NextToken()
+	}
+
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.depth++
+	case token.RBRACE:
+		// a stray closing brace at the top level does not close anything
+		if p.depth > 0 {
+			p.depth--
+		}
+	}
 }
 
 // ParseProgram - recursive descent parser
+// broken statements are kept in the program as partial nodes (see ast.BadExpression)
 func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 
 	for p.curToken.Type != token.EOF {
 		stmt := p.parseStatement()
+		if p.panicking {
+			p.synchronize(0)
+		}
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
@@ -185,6 +253,28 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// synchronize - panic mode recovery. after a syntax error, skip tokens until the end of the
+// broken statement, so the next statement can be parsed from a clean state.
+// the statement ends at a ';', right before 'let', 'return' or the '}' closing the
+// enclosing block, or at the '}' of the enclosing block if the broken statement already
+// consumed it. depth is the brace depth of the block the statement belongs to
+func (p *Parser) synchronize(depth int) {
+	for !p.curTokenIs(token.EOF) && p.depth >= depth {
+		if p.depth == depth {
+			if p.curTokenIs(token.SEMICOLON) {
+				break
+			}
+			if p.peekTokenIs(token.LET) || p.peekTokenIs(token.RETURN) ||
+				p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) {
+				break
+			}
+		}
+		p.nextToken()
+	}
+
+	p.panicking = false
+}
+
 // parseStatement - parse a statement
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
@@ -204,10 +294,15 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 
 	if prefix == nil {
 		p.noPrefixParseFnError(p.curToken.Type)
-		return nil
+		return &ast.BadExpression{Token: p.curToken}
 	}
 
+	// parse functions return nil on errors, keep a placeholder in the AST instead
+	start := p.curToken
 	leftExpression := prefix()
+	if leftExpression == nil {
+		leftExpression = &ast.BadExpression{Token: start}
+	}
 
 	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
@@ -218,6 +313,9 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 		p.nextToken()
 
 		leftExpression = infix(leftExpression)
+		if leftExpression == nil {
+			leftExpression = &ast.BadExpression{Token: start}
+		}
 	}
 
 	return leftExpression
@@ -227,24 +325,35 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 // constructs *ast.LetStatement using the currentTooken
 // advances token by calling expectPeek()
 // after parsing the identifier, the parser expects
-// an '=' sign and a semicolon (token.ASSIGN and token.SEMICOLON)
-func (p *Parser) parseLetStatement() *ast.LetStatement {
+// an '=' sign followed by an expression and an optional semicolon.
+// on errors, a let statement without a name is returned as an *ast.BadStatement,
+// and a missing value is replaced by an *ast.BadExpression
+func (p *Parser) parseLetStatement() ast.Statement {
 	// construct a let statement ast node
 	stmt := &ast.LetStatement{Token: p.curToken}
 
 	// assert an identifier, and construct one right below
 	if !p.expectPeek(token.IDENT) {
-		return nil
+		return &ast.BadStatement{Token: stmt.Token}
 	}
 
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	// assert an assignment
 	if !p.expectPeek(token.ASSIGN) {
-		return nil
+		stmt.Value = &ast.BadExpression{Token: p.peekToken}
+		return stmt
 	}
 
-	// TODO: Skipping expressions until we encounter a semicolon
-	for !p.curTokenIs(token.SEMICOLON) {
+	if !p.expectPeekExpression("=") {
+		stmt.Value = &ast.BadExpression{Token: p.peekToken}
+		return stmt
+	}
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	// semicolons are optional, same as expression statements
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -254,10 +363,15 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 // parseReturnStatement - parse a return statement
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
+	if !p.expectPeekExpression("return") {
+		stmt.ReturnValue = &ast.BadExpression{Token: p.peekToken}
+		return stmt
+	}
 	p.nextToken()
 
-	// TODO: implement expressions until semicolon
-	for !p.curTokenIs(token.SEMICOLON) {
+	stmt.ReturnValue = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -278,10 +392,286 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	return stmt
 }
 
+// parseGroupedExpression - ( <expression> )
+// parsing the inner expression with the lowest precedence is all it takes
+// for the parens to override the precedence of the surrounding operators
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	p.nextToken()
+
+	exp := p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return exp
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
 
+// parseIfExpression - if (<condition>) { <consequence> } else { <alternative> }
+// the else branch is optional, and may itself be another if expression (else if chains)
+func (p *Parser) parseIfExpression() ast.Expression {
+	expression := &ast.IfExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Consequence = p.parseBlockStatement()
+
+	if !p.peekTokenIs(token.ELSE) {
+		return expression
+	}
+	p.nextToken()
+
+	// else if - wrap the nested if expression in a block of its own
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		block := &ast.BlockStatement{Token: p.curToken}
+		stmt := &ast.ExpressionStatement{Token: p.curToken}
+		stmt.Expression = p.parseIfExpression()
+		if stmt.Expression == nil {
+			return nil
+		}
+		block.Statements = []ast.Statement{stmt}
+		expression.Alternative = block
+		return expression
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Alternative = p.parseBlockStatement()
+
+	return expression
+}
+
+// parseBlockStatement - parse statements until the closing brace (or EOF)
+// expects curToken to be the opening brace
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.curToken}
+	block.Statements = []ast.Statement{}
+
+	depth := p.depth
+	p.nextToken()
+
+	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		stmt := p.parseStatement()
+		if p.panicking {
+			p.synchronize(depth)
+		}
+		if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+		// the broken statement ran into the closing brace of this block
+		if p.depth < depth {
+			break
+		}
+		p.nextToken()
+	}
+
+	// nested blocks all end at EOF, only the innermost one reports it
+	if p.curTokenIs(token.EOF) && !p.reportedUnclosedBlock() {
+		msg := fmt.Sprintf("expected %s to close block, got %s instead", token.RBRACE, token.EOF)
+		p.addError(p.curToken, ErrUnclosedBlock, token.RBRACE, msg)
+	}
+
+	return block
+}
+
+// reportedUnclosedBlock - the last error already reports an unclosed block at the current token
+func (p *Parser) reportedUnclosedBlock() bool {
+	if len(p.errors) == 0 {
+		return false
+	}
+	last := p.errors[len(p.errors)-1]
+	return last.Code == ErrUnclosedBlock && last.Pos == p.curToken.Pos
+}
+
+// parseFunctionLiteral - fn(<parameters>) { <body> }
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	literal := &ast.FunctionLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	literal.Parameters = p.parseFunctionParameters()
+	if literal.Parameters == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	literal.Body = p.parseBlockStatement()
+
+	return literal
+}
+
+// parseFunctionParameters - comma separated list of identifiers, e.g: (x, y, z)
+// expects curToken to be the opening paren. returns nil on errors
+func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+	identifiers := []*ast.Identifier{}
+
+	// no parameters
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return identifiers
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	identifiers = append(identifiers, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		identifiers = append(identifiers, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return identifiers
+}
+
+// parseCallExpression - registered as the infix parse function of '('.
+// 'function' is whatever came before the paren (an identifier or a function literal)
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	expression := &ast.CallExpression{Token: p.curToken, Function: function}
+	expression.Arguments = p.parseExpressionList(token.RPAREN)
+	if expression.Arguments == nil {
+		return nil
+	}
+	return expression
+}
+
+// parseExpressionList - comma separated list of expressions, e.g: (1, a + b, add(2, 3)) or [1, 2, 3]
+// expects curToken to be the opening token, and stops at end. returns nil on errors
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
+
+	// empty list
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return list
+	}
+
+	p.nextToken()
+	list = append(list, p.parseExpression(LOWEST))
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		list = append(list, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+
+	return list
+}
+
+// parseArrayLiteral - [<comma separated expressions>]
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.curToken}
+
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if array.Elements == nil {
+		return nil
+	}
+
+	return array
+}
+
+// parseHashLiteral - {<key>: <value>, ...}
+// block statements are only parsed where the grammar expects them (e.g: after 'if' or 'fn'),
+// so a brace in expression position always starts a hash literal
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken, Pairs: []ast.HashPair{}}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return hash
+}
+
+// parseIndexExpression - registered as the infix parse function of '['.
+// left is the expression being indexed, e.g: myArray[1 + 1]
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	expression := &ast.IndexExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+	expression.Index = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return expression
+}
+
+// parseFloatLiteral - convert the literal to a float64
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	literal := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.addError(p.curToken, ErrInvalidFloat, "", msg)
+		return nil
+	}
+	literal.Value = value
+
+	return literal
+}
+
+// parseStringLiteral - the lexer already replaced the escape sequences
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+}
+
 // parseIntegerLiteral -
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	literal := &ast.IntegerLiteral{Token: p.curToken}
@@ -291,7 +681,7 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.curToken, ErrInvalidInteger, "", msg)
 		return nil
 	}
 	literal.Value = value
@@ -324,5 +714,35 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 // peekError - add an error to the errors slide (in the parser)
 func (p *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken, ErrUnexpectedToken, t, msg)
+}
+
+// expectPeekExpression - make sure an expression starts at peekToken.
+// used after tokens that must be followed by a value (e.g: 'let x =' and 'return')
+func (p *Parser) expectPeekExpression(after string) bool {
+	if p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) {
+		msg := fmt.Sprintf("expected expression after %s, got %s instead", after, p.peekToken.Type)
+		p.addError(p.peekToken, ErrMissingExpression, "", msg)
+		return false
+	}
+	return true
+}
+
+// addError - record an error at the position of tok.
+// expected is the token type the parser was looking for (empty if there is none).
+// only the first error of a statement is recorded, the rest are likely caused by it
+func (p *Parser) addError(tok token.Token, code ErrorCode, expected token.TokenType, msg string) {
+	if p.panicking {
+		return
+	}
+	p.panicking = true
+
+	p.errors = append(p.errors, &ParseError{
+		Pos:      tok.Pos,
+		End:      tok.End,
+		Code:     code,
+		Expected: expected,
+		Got:      tok.Type,
+		Msg:      msg,
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"monkeylang/ast"
 	"monkeylang/lexer"
+	"monkeylang/token"
 	"testing"
 )
 
@@ -12,36 +13,35 @@ import (
 // Set expectations on what the AST to produce
 // Check as many fields of the AST as possible
 func TestLetStatements(t *testing.T) {
-	input := `
-	let x = 5;
-	let y = 10;
-	let foobar = 838383;
-	`
-	l := lexer.New(input)
-	p := New(l)
-
-	program := p.ParseProgram()
-	checkParserErrors(t, p)
-	if program == nil {
-		t.Fatalf("ParseProgram() returned nil")
-	}
-	if len(program.Statements) != 3 {
-		t.Fatalf("program.Statements does not contain 3 statements, got=%d", len(program.Statements))
-	}
-
 	tests := []struct {
+		input              string
 		expectedIdentifier string
+		expectedValue      interface{}
 	}{
-		{"x"},
-		{"y"},
-		{"foobar"},
+		{"let x = 5;", "x", 5},
+		{"let y = 10", "y", 10},
+		{"let foobar = y;", "foobar", "y"},
 	}
 
-	for i, tt := range tests {
-		stmt := program.Statements[i]
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement, got=%d", len(program.Statements))
+		}
+
+		stmt := program.Statements[0]
 		if !testLetStatement(t, stmt, tt.expectedIdentifier) {
 			return
 		}
+
+		value := stmt.(*ast.LetStatement).Value
+		if !testLiteralExpression(t, value, tt.expectedValue) {
+			return
+		}
 	}
 }
 
@@ -86,30 +86,60 @@ func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 }
 
 func TestReturnStatements(t *testing.T) {
-	input := `
-	return 5;
-	return 10;
-	return 993322;
-	`
-	l := lexer.New(input)
-	p := New(l)
+	tests := []struct {
+		input         string
+		expectedValue interface{}
+	}{
+		{"return 5;", 5},
+		{"return 10", 10},
+		{"return foobar;", "foobar"},
+	}
 
-	program := p.ParseProgram()
-	checkParserErrors(t, p)
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
 
-	if len(program.Statements) != 3 {
-		t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(program.Statements))
-	}
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
 
-	for _, stmt := range program.Statements {
-		returnStmt, ok := stmt.(*ast.ReturnStatement)
+		returnStmt, ok := program.Statements[0].(*ast.ReturnStatement)
 		if !ok {
-			t.Errorf("stmt not *ast.ReturnStatement. got=%T", stmt)
-			continue
+			t.Fatalf("stmt not *ast.ReturnStatement. got=%T", program.Statements[0])
 		}
 		if returnStmt.TokenLiteral() != "return" {
 			t.Errorf("returnStmt.TokenLiteral not 'return', got %q", returnStmt.TokenLiteral())
 		}
+		if !testLiteralExpression(t, returnStmt.ReturnValue, tt.expectedValue) {
+			return
+		}
+	}
+}
+
+func TestMissingExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let x = ;", "expected expression after =, got ; instead"},
+		{"let x =", "expected expression after =, got EOF instead"},
+		{"return;", "expected expression after return, got ; instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 parser error for %q, got=%d (%v)", tt.input, len(errors), errors)
+		}
+		if errors[0].Msg != tt.expectedError {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expectedError, errors[0].Msg)
+		}
 	}
 }
 
@@ -145,6 +175,35 @@ func TestIdentifierExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0xFF_FF", 65535},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value for %q not %d. got=%d", tt.input, tt.expected, literal.Value)
+		}
+	}
+}
+
 func TestIntegerLiteralExpression(t *testing.T) {
 	input := "5;"
 
@@ -248,6 +307,11 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 < 5;", 5, "<", 5},
 		{"5 == 5;", 5, "==", 5},
 		{"5 != 5;", 5, "!=", 5},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"5 % 5;", 5, "%", 5},
+		{"5 && 5;", 5, "&&", 5},
+		{"5 || 5;", 5, "||", 5},
 	}
 
 	for _, tt := range infixTests {
@@ -316,6 +380,62 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
             "a / b / c",
             "((a / b) / c)",
         },
+        {
+            "1 + (2 + 3) + 4",
+            "((1 + (2 + 3)) + 4)",
+        },
+        {
+            "(5 + 5) * 2",
+            "((5 + 5) * 2)",
+        },
+        {
+            "2 / (5 + 5)",
+            "(2 / (5 + 5))",
+        },
+        {
+            "-(5 + 5)",
+            "(-(5 + 5))",
+        },
+        {
+            "!(true == true)",
+            "(!(true == true))",
+        },
+        {
+            "((1 + 2) * (3 - (4 / a))) * b",
+            "(((1 + 2) * (3 - (4 / a))) * b)",
+        },
+        {
+            "(((a)))",
+            "a",
+        },
+        {
+            "add((a + b) * c)",
+            "add(((a + b) * c))",
+        },
+        {
+            "a + b % c * d",
+            "(a + ((b % c) * d))",
+        },
+        {
+            "x >= 0 && x < n",
+            "((x >= 0) && (x < n))",
+        },
+        {
+            "a || b && c",
+            "(a || (b && c))",
+        },
+        {
+            "a && b || c && d",
+            "((a && b) || (c && d))",
+        },
+        {
+            "a == b && c != d || !e",
+            "(((a == b) && (c != d)) || (!e))",
+        },
+        {
+            "a <= b == c >= d",
+            "((a <= b) == (c >= d))",
+        },
     }
 
     for _, tt := range tests {
@@ -423,4 +543,728 @@ func testInfixEpxression(t *testing.T, exp ast.Expression, left interface{}, ope
 // 			t.Errorf("boolean.Value not %t, got %t", tt.expectedBool, boolean.Value)
 // 		}
 // 	}
-// }
\ No newline at end of file
+// }
+func TestIfExpression(t *testing.T) {
+	input := `if (x < y) { x }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+
+	if !testInfixEpxression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(exp.Consequence.Statements) != 1 {
+		t.Errorf("consequence is not 1 statement. got=%d", len(exp.Consequence.Statements))
+	}
+
+	consequence, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T", exp.Consequence.Statements[0])
+	}
+
+	if !testIdentifier(t, consequence.Expression, "x") {
+		return
+	}
+
+	if exp.Alternative != nil {
+		t.Errorf("exp.Alternative was not nil. got=%+v", exp.Alternative)
+	}
+}
+
+func TestIfElseExpression(t *testing.T) {
+	input := `if (x < y) { x } else { y }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+
+	if !testInfixEpxression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	if exp.Alternative == nil {
+		t.Fatalf("exp.Alternative was nil")
+	}
+
+	if len(exp.Alternative.Statements) != 1 {
+		t.Fatalf("alternative is not 1 statement. got=%d", len(exp.Alternative.Statements))
+	}
+
+	alternative, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T", exp.Alternative.Statements[0])
+	}
+
+	if !testIdentifier(t, alternative.Expression, "y") {
+		return
+	}
+}
+
+func TestElseIfExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"if (x) { a } else if (y) { b }", "ifx aelse ify b"},
+		{"if (x) { a } else if (y) { b } else { c }", "ifx aelse ify belse c"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+
+		exp := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+		nested, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("alternative is not ast.ExpressionStatement. got=%T", exp.Alternative.Statements[0])
+		}
+		if _, ok := nested.Expression.(*ast.IfExpression); !ok {
+			t.Fatalf("alternative is not ast.IfExpression. got=%T", nested.Expression)
+		}
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `fn(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function literal parameters wrong. want 2, got=%d", len(function.Parameters))
+	}
+
+	testLiteralExpression(t, function.Parameters[0], "x")
+	testLiteralExpression(t, function.Parameters[1], "y")
+
+	if len(function.Body.Statements) != 1 {
+		t.Fatalf("function.Body.Statements has not 1 statement. got=%d", len(function.Body.Statements))
+	}
+
+	bodyStmt, ok := function.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("function body stmt is not ast.ExpressionStatement. got=%T", function.Body.Statements[0])
+	}
+
+	testInfixEpxression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
+func TestFunctionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{input: "fn() {};", expectedParams: []string{}},
+		{input: "fn(x) {};", expectedParams: []string{"x"}},
+		{input: "fn(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Errorf("length parameters wrong. want %d, got=%d", len(tt.expectedParams), len(function.Parameters))
+		}
+
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, function.Parameters[i], ident)
+		}
+	}
+}
+
+func TestCallExpressionParsing(t *testing.T) {
+	input := "add(1, 2 * 3, 4 + 5);"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Function, "add") {
+		return
+	}
+
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+
+	testLiteralExpression(t, exp.Arguments[0], 1)
+	testInfixEpxression(t, exp.Arguments[1], 2, "*", 3)
+	testInfixEpxression(t, exp.Arguments[2], 4, "+", 5)
+}
+
+func TestCallExpressionPrecedence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a + add(b * c) + d", "((a + add((b * c))) + d)"},
+		{"add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))", "add(a, b, 1, (2 * 3), (4 + 5), add(6, (7 * 8)))"},
+		{"add(a + b + c * d / f + g)", "add((((a + b) + ((c * d) / f)) + g))"},
+		{"let add = fn(a, b) { a + b }; add(1, 2)", "let add = fn(a, b) (a + b);add(1, 2)"},
+		{"fn(x) { x }(5)", "fn(x) x(5)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestGroupedExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"(1 + 2", "expected next token to be ), got EOF instead"},
+		{"(1 + 2;", "expected next token to be ), got ; instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0].Msg != tt.expectedError {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expectedError, errors[0].Msg)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedCode     ErrorCode
+		expectedExpected token.TokenType
+		expectedGot      token.TokenType
+		expectedError    string
+	}{
+		{"let x 5;", ErrUnexpectedToken, token.ASSIGN, token.INT, "1:7: expected next token to be =, got INT instead"},
+		{"let = 5;", ErrUnexpectedToken, token.IDENT, token.ASSIGN, "1:5: expected next token to be IDENT, got = instead"},
+		{"\n  *5", ErrNoPrefixParseFn, "", token.ASTERISK, "2:3: no prefix parse function for * found"},
+		{"99999999999999999999", ErrInvalidInteger, "", token.INT, "1:1: could not parse \"99999999999999999999\" as integer"},
+		{"let x = ;", ErrMissingExpression, "", token.SEMICOLON, "1:9: expected expression after =, got ; instead"},
+		{"if (x) { x", ErrUnclosedBlock, token.RBRACE, token.EOF, "1:11: expected } to close block, got EOF instead"},
+		{"let x = \xff;", ErrIllegalToken, "", token.ILLEGAL, "1:9: invalid UTF-8 encoding (byte 0xff)"},
+		{"let x = 1 @ 2", ErrIllegalToken, "", token.ILLEGAL, "1:11: unexpected character '@'"},
+		{"let mask = 0x;", ErrIllegalToken, "", token.ILLEGAL, "1:12: hexadecimal literal has no digits"},
+		{"let n = 1__0;", ErrIllegalToken, "", token.ILLEGAL, "1:10: '_' must separate successive digits"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+
+		err := errors[0]
+		if err.Code != tt.expectedCode {
+			t.Errorf("wrong error code for %q. expected=%q, got=%q", tt.input, tt.expectedCode, err.Code)
+		}
+		if err.Expected != tt.expectedExpected {
+			t.Errorf("wrong expected token for %q. expected=%q, got=%q", tt.input, tt.expectedExpected, err.Expected)
+		}
+		if err.Got != tt.expectedGot {
+			t.Errorf("wrong got token for %q. expected=%q, got=%q", tt.input, tt.expectedGot, err.Got)
+		}
+		if err.Error() != tt.expectedError {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expectedError, err.Error())
+		}
+	}
+}
+
+func TestRenderParseError(t *testing.T) {
+	input := "let a = 1;\n\tlet add fn(x) { x };"
+
+	l := lexer.NewFile("script.mk", input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	expected := "script.mk:2:10: error[P001]: expected next token to be =, got FUNCTION instead\n" +
+		"\tlet add fn(x) { x };\n" +
+		"\t        ^^\n"
+
+	if actual := errors[0].Render(input); actual != expected {
+		t.Errorf("rendered error wrong.\nexpected=%q\ngot=%q", expected, actual)
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors []string
+		expected       string // program.String() of the partial AST
+	}{
+		{
+			"let = 5; let y = 10;",
+			[]string{"expected next token to be IDENT, got = instead"},
+			"<bad statement>let y = 10;",
+		},
+		{
+			"let x 5; let y = 10;",
+			[]string{"expected next token to be =, got INT instead"},
+			"let x = <bad expression>;let y = 10;",
+		},
+		{
+			"let x = 5 + ;\nreturn x",
+			[]string{"no prefix parse function for ; found"},
+			"let x = (5 + <bad expression>);return x;",
+		},
+		{
+			"let f = fn(x) { let = 1; x + }; let z = 3",
+			[]string{
+				"expected next token to be IDENT, got = instead",
+				"no prefix parse function for } found",
+			},
+			"let f = fn(x) <bad statement>(x + <bad expression>);let z = 3;",
+		},
+		{
+			"if (x { y }\nlet a = 1;",
+			[]string{"expected next token to be ), got { instead"},
+			"<bad expression>let a = 1;",
+		},
+		{
+			"fn(x) { return }; add(1, 2)",
+			[]string{"expected expression after return, got } instead"},
+			"fn(x) return <bad expression>;add(1, 2)",
+		},
+		{
+			"add(1, 2 3); let b = 2;",
+			[]string{"expected next token to be ), got INT instead"},
+			"<bad expression>let b = 2;",
+		},
+		{
+			"let f = fn() { if (true) { 1",
+			[]string{"expected } to close block, got EOF instead"},
+			"let f = fn() iftrue 1;",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Errorf("wrong number of errors for %q. expected=%d, got=%d (%v)", tt.input, len(tt.expectedErrors), len(errors), errors)
+			continue
+		}
+
+		for i, msg := range tt.expectedErrors {
+			if errors[i].Msg != msg {
+				t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, msg, errors[i].Msg)
+			}
+		}
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("wrong partial AST for %q. expected=%q, got=%q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestRenderParseErrorUnicode(t *testing.T) {
+	input := "let größe 5;"
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 parser error, got=%d", len(errors))
+	}
+
+	expected := "1:11: error[P001]: expected next token to be =, got INT instead\n" +
+		"let größe 5;\n" +
+		"          ^\n"
+
+	if actual := errors[0].Render(input); actual != expected {
+		t.Errorf("rendered error wrong.\nexpected=%q\ngot=%q", expected, actual)
+	}
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"hello \"world\"\n";`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+	}
+
+	if literal.Value != "hello \"world\"\n" {
+		t.Errorf("literal.Value not %q. got=%q", "hello \"world\"\n", literal.Value)
+	}
+
+	if program.String() != `"hello \"world\"\n"` {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+}
+
+func TestUnterminatedString(t *testing.T) {
+	input := "let a = \"hello;\nlet b = 2;"
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 parser error, got=%d (%v)", len(errors), errors)
+	}
+	if errors[0].Error() != "1:9: unterminated string" {
+		t.Errorf("wrong error. got=%q", errors[0].Error())
+	}
+	if errors[0].Code != ErrIllegalToken {
+		t.Errorf("wrong error code. got=%q", errors[0].Code)
+	}
+}
+
+func TestParsingWithComments(t *testing.T) {
+	input := `// add two numbers
+let add = fn(a, b) {
+	a + b // the sum
+};
+/* call it */ add(1, /* two */ 2)`
+	expected := "let add = fn(a, b) (a + b);add(1, 2)"
+
+	for _, mode := range []lexer.Mode{0, lexer.ScanComments} {
+		l := lexer.New(input)
+		l.SetMode(mode)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != expected {
+			t.Errorf("expected=%q, got=%q", expected, actual)
+		}
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5;", 1.5},
+		{"2e3", 2000},
+		{"1.5e-3", 0.0015},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+	}
+
+	l := lexer.New("1e999")
+	p := New(l)
+	p.ParseProgram()
+	if errors := p.Errors(); len(errors) != 1 || errors[0].Code != ErrInvalidFloat {
+		t.Errorf("expected out of range float error. got=%v", errors)
+	}
+}
+
+func TestParsingArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(array.Elements) != 3 {
+		t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
+	}
+
+	testIntegerLiteral(t, array.Elements[0], 1)
+	testInfixEpxression(t, array.Elements[1], 2, "*", 2)
+	testInfixEpxression(t, array.Elements[2], 3, "+", 3)
+}
+
+func TestParsingEmptyArrayLiteral(t *testing.T) {
+	l := lexer.New("[]")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+	}
+	if len(array.Elements) != 0 {
+		t.Errorf("len(array.Elements) not 0. got=%d", len(array.Elements))
+	}
+}
+
+func TestParsingIndexExpressions(t *testing.T) {
+	input := "myArray[1 + 1]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.IndexExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, indexExp.Left, "myArray") {
+		return
+	}
+
+	if !testInfixEpxression(t, indexExp.Index, 1, "+", 1) {
+		return
+	}
+}
+
+func TestIndexPrecedence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a * [1, 2, 3, 4][b * c] * d", "((a * ([1, 2, 3, 4][(b * c)])) * d)"},
+		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))"},
+		{"-a[0]", "(-(a[0]))"},
+		{"a[0][1]", "((a[0])[1])"},
+		{"f(x)[0]", "(f(x)[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestParsingHashLiteralsStringKeys(t *testing.T) {
+	input := `{"one": 1, "two": 2, "three": 3}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+
+	expected := []struct {
+		key   string
+		value int64
+	}{
+		{"one", 1},
+		{"two", 2},
+		{"three", 3},
+	}
+
+	for i, pair := range hash.Pairs {
+		literal, ok := pair.Key.(*ast.StringLiteral)
+		if !ok {
+			t.Errorf("key is not ast.StringLiteral. got=%T", pair.Key)
+			continue
+		}
+		if literal.Value != expected[i].key {
+			t.Errorf("key[%d] wrong. expected=%q, got=%q", i, expected[i].key, literal.Value)
+		}
+		testIntegerLiteral(t, pair.Value, expected[i].value)
+	}
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	l := lexer.New("{}")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 0 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+}
+
+func TestParsingHashLiteralsWithExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"one": 0 + 1, "two": 10 - 8, "three": 15 / 5}`, `{"one": (0 + 1), "two": (10 - 8), "three": (15 / 5)}`},
+		{`{1: true, false: "no"}`, `{1: true, false: "no"}`},
+		{`let config = {"debug": true}; config["debug"]`, `let config = {"debug": true};(config["debug"])`},
+		{`if (x) { {"a": 1} }`, `ifx {"a": 1}`},
+		{`fn() { {} }`, `fn() {}`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestHashLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{`{"a" 1}`, "expected next token to be :, got INT instead"},
+		{`{"a": 1 "b": 2}`, "expected next token to be ,, got STRING instead"},
+		{`{"a": 1`, "expected next token to be ,, got EOF instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 parser error for %q, got=%d (%v)", tt.input, len(errors), errors)
+		}
+		if errors[0].Msg != tt.expectedError {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expectedError, errors[0].Msg)
+		}
+	}
+}
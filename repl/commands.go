@@ -0,0 +1,183 @@
+package repl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"monkeylang/object"
+	"strings"
+	"time"
+)
+
+// commandHelp - usage and description of every REPL command, in the order :help prints them
+var commandHelp = [][2]string{
+	{":help", "show this help"},
+	{":env", "list the bindings of the environment"},
+	{":reset", "remove all bindings and clear the history"},
+	{":load <file>", "evaluate a file in the current environment"},
+	{":save <file>", "write every input evaluated without errors to a file"},
+	{":type <expr>", "evaluate an expression and print the type of the result"},
+	{":ast [expr]", "print the AST of an expression, or toggle printing the AST of every input"},
+	{":tokens [expr]", "print the tokens of an expression, or toggle printing the tokens of every input"},
+	{":time <expr>", "evaluate an expression and print how long it took"},
+	{":quit", "leave the REPL"},
+}
+
+// command - run a REPL command (a line starting with ':'). returns true if the REPL should stop
+func (s *session) command(line string) (quit bool) {
+	name, arg := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		name, arg = line[:i], strings.TrimSpace(line[i+1:])
+	}
+
+	switch name {
+	case ":quit", ":q":
+		return true
+	case ":help":
+		s.help()
+	case ":env":
+		s.printEnv()
+	case ":reset":
+		s.reset()
+	case ":load":
+		s.load(arg)
+	case ":save":
+		s.save(arg)
+	case ":type":
+		s.printType(arg)
+	case ":ast":
+		if arg == "" {
+			s.toggle(astMode)
+		} else {
+			printAST(s.out, arg)
+		}
+	case ":tokens":
+		if arg == "" {
+			s.toggle(tokensMode)
+		} else {
+			printTokens(s.out, arg)
+		}
+	case ":time":
+		s.time(arg)
+	default:
+		fmt.Fprintf(s.out, "unknown command %s, type :help for a list of commands\n", name)
+	}
+	return false
+}
+
+// help - print every command with its description
+func (s *session) help() {
+	for _, c := range commandHelp {
+		fmt.Fprintf(s.out, "%-16s %s\n", c[0], c[1])
+	}
+}
+
+// printEnv - print every binding as name = value
+func (s *session) printEnv() {
+	names := s.env.Names()
+	if len(names) == 0 {
+		fmt.Fprintln(s.out, "no bindings")
+		return
+	}
+	for _, name := range names {
+		val, _ := s.env.Get(name)
+		fmt.Fprintf(s.out, "%s = %s\n", name, val.Inspect())
+	}
+}
+
+// reset - start over with an empty environment and history
+func (s *session) reset() {
+	s.env = s.newEnvironment()
+	s.history = nil
+	fmt.Fprintln(s.out, "environment reset")
+}
+
+// load - evaluate a file in the session environment and print the result
+func (s *session) load(filename string) {
+	if !s.requireArg(":load <file>", filename) {
+		return
+	}
+	source, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(s.out, "cannot load file: %v\n", err)
+		return
+	}
+
+	evaluated, _ := s.eval(filename, string(source))
+	if evaluated != nil {
+		fmt.Fprintln(s.out, evaluated.Inspect())
+	}
+}
+
+// save - write the history to a file, so it can be loaded again with :load.
+// expressions evaluated by :type and :time are not part of the history
+func (s *session) save(filename string) {
+	if !s.requireArg(":save <file>", filename) {
+		return
+	}
+	var out strings.Builder
+	for _, input := range s.history {
+		out.WriteString(input)
+		out.WriteString("\n")
+	}
+	if err := ioutil.WriteFile(filename, []byte(out.String()), 0644); err != nil {
+		fmt.Fprintf(s.out, "cannot save file: %v\n", err)
+		return
+	}
+	fmt.Fprintf(s.out, "saved %d inputs to %s\n", len(s.history), filename)
+}
+
+// printType - evaluate expr and print the type of the result. errors are printed as they are
+func (s *session) printType(expr string) {
+	if !s.requireArg(":type <expr>", expr) {
+		return
+	}
+	evaluated, _ := s.evaluate("", expr)
+	switch {
+	case evaluated == nil:
+	case evaluated.Type() == object.ERROR_OBJ:
+		fmt.Fprintln(s.out, evaluated.Inspect())
+	default:
+		fmt.Fprintln(s.out, evaluated.Type())
+	}
+}
+
+// time - evaluate expr, print the result and how long parsing and evaluating it took
+func (s *session) time(expr string) {
+	if !s.requireArg(":time <expr>", expr) {
+		return
+	}
+	start := time.Now()
+	evaluated, ok := s.evaluate("", expr)
+	elapsed := time.Since(start)
+	if !ok {
+		return
+	}
+	if evaluated != nil {
+		fmt.Fprintln(s.out, evaluated.Inspect())
+	}
+	fmt.Fprintf(s.out, "elapsed: %s\n", elapsed)
+}
+
+// toggle - switch to m, or back to evaluating if m is already active
+func (s *session) toggle(m mode) {
+	switch {
+	case s.mode == m:
+		s.mode = evalMode
+		fmt.Fprintln(s.out, "evaluating input")
+	case m == tokensMode:
+		s.mode = m
+		fmt.Fprintln(s.out, "printing tokens")
+	default:
+		s.mode = m
+		fmt.Fprintln(s.out, "printing the AST")
+	}
+}
+
+// requireArg - print the usage of a command if its argument is missing
+func (s *session) requireArg(usage, arg string) bool {
+	if arg == "" {
+		fmt.Fprintf(s.out, "usage: %s\n", usage)
+		return false
+	}
+	return true
+}
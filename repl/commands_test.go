@@ -0,0 +1,96 @@
+package repl
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{":env\n", "no bindings\n"},
+		{"let b = 2;\nlet a = [1];\n:env\n", "a = [1]\nb = 2\n"},
+		{"let a = 1;\n:reset\n:env\n", "environment reset\nno bindings\n"},
+		{":reset\nputs(1)\n", "environment reset\n1\nnull\n"},
+		{":type 1 + 2\n", "INTEGER\n"},
+		{`:type "a"` + "\n", "STRING\n"},
+		{":type len\n", "BUILTIN\n"},
+		{":type 1 + true\n", "TypeError: type mismatch: INTEGER + BOOLEAN at 1:3\n"},
+		{":type\n", "usage: :type <expr>\n"},
+		{":ast 1 + 2 * 3\n", "(1 + (2 * 3))\n"},
+		{":ast let x 1\n", "1:7: error[P001]: expected next token to be =, got INT instead\nlet x 1\n      ^\n"},
+		{":tokens 1\n", "{Type:INT Literal:1 Pos:1:1 End:1:2}\n"},
+		{":foo\n", "unknown command :foo, type :help for a list of commands\n"},
+		{"1\n:quit\n2\n", "1\n"},
+		{"  :q  \n2\n", ""},
+		{":load\n", "usage: :load <file>\n"},
+	}
+
+	for _, tt := range tests {
+		if got := run(tt.input); got != tt.expected {
+			t.Errorf("input %q: wrong output. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestHelpCommand(t *testing.T) {
+	got := run(":help\n")
+	for _, c := range commandHelp {
+		if !strings.Contains(got, c[0]) {
+			t.Errorf("help does not mention %s. got=%q", c[0], got)
+		}
+	}
+}
+
+func TestTimeCommand(t *testing.T) {
+	got := run(":time 2 * 21\n")
+	if !strings.HasPrefix(got, "42\nelapsed: ") {
+		t.Errorf("wrong output. got=%q", got)
+	}
+
+	if got := run(":time let x\n"); strings.Contains(got, "elapsed") {
+		t.Errorf("input with parse errors is timed. got=%q", got)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	session := filepath.Join(dir, "session.mk")
+
+	// inputs with errors and expressions evaluated by :type and :time are not saved
+	input := "let add = fn(a, b) {\n  a + b\n};\nlet x 1\n1 + true\n:type add(1, 2)\n:time add(3, 4)\nlet y = add(1, 2);\n:save " + session + "\n"
+	got := run(input)
+	if !strings.HasSuffix(got, "saved 2 inputs to "+session+"\n") {
+		t.Fatalf("wrong output. got=%q", got)
+	}
+
+	saved, err := ioutil.ReadFile(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "let add = fn(a, b) {\n  a + b\n};\nlet y = add(1, 2);\n"
+	if string(saved) != expected {
+		t.Errorf("wrong file content. expected=%q, got=%q", expected, string(saved))
+	}
+
+	if got := run(":load " + session + "\nadd(y, 10)\n"); got != "13\n" {
+		t.Errorf("wrong output after :load. got=%q", got)
+	}
+
+	broken := filepath.Join(dir, "broken.mk")
+	if err := ioutil.WriteFile(broken, []byte("let f = fn() {\n  1 + true\n};\nf()\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expected = "TypeError: type mismatch: INTEGER + BOOLEAN at " + broken + ":2:5\n  in f, called at " + broken + ":4:1\n"
+	if got := run(":load " + broken + "\n"); got != expected {
+		t.Errorf("wrong output for runtime error. expected=%q, got=%q", expected, got)
+	}
+
+	if got := run(":load " + filepath.Join(dir, "missing.mk") + "\n"); !strings.HasPrefix(got, "cannot load file: ") {
+		t.Errorf("missing file is not reported. got=%q", got)
+	}
+}
@@ -0,0 +1,432 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errInterrupted - the user pressed Ctrl-C, the current input is discarded
+var errInterrupted = errors.New("interrupted")
+
+// key - a rune read from the terminal, or one of the special keys below
+type key rune
+
+// special keys, encoded by the terminal as escape sequences (e.g: "\x1b[A" is the up arrow)
+const (
+	keyNone key = utf8.MaxRune + 1 + iota // unknown escape sequence, ignored
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+)
+
+// control characters
+const (
+	ctrlA     key = 1
+	ctrlB     key = 2
+	ctrlC     key = 3
+	ctrlD     key = 4
+	ctrlE     key = 5
+	ctrlF     key = 6
+	ctrlG     key = 7
+	ctrlH     key = 8
+	tab       key = 9
+	ctrlJ     key = 10
+	ctrlK     key = 11
+	ctrlL     key = 12
+	enter     key = 13
+	ctrlN     key = 14
+	ctrlP     key = 16
+	ctrlR     key = 18
+	ctrlU     key = 21
+	ctrlW     key = 23
+	escape    key = 27
+	backspace key = 127
+)
+
+// editor - minimal line editor for terminals in raw mode, in the spirit of linenoise:
+// cursor movement, history (up/down and Ctrl-R search) and tab completion.
+// the line is redrawn in place after every key, long lines are not wrapped
+// and every rune is assumed to be one column wide
+type editor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history *history
+	// complete - every word that can be completed, the editor picks the ones matching the word before the cursor
+	complete func() []string
+	// raw - switch the terminal to raw mode while a line is read, nil if the input is not a terminal
+	raw func() (restore func() error, err error)
+
+	prompt string
+	buf    []rune
+	pos    int // cursor position in buf
+}
+
+func newEditor(in io.Reader, out io.Writer, h *history, complete func() []string) *editor {
+	return &editor{in: bufio.NewReader(in), out: out, history: h, complete: complete}
+}
+
+// readLine - read a line, showing prompt. returns io.EOF for Ctrl-D on an empty line
+// and errInterrupted for Ctrl-C. non-empty lines are added to the history
+func (e *editor) readLine(prompt string) (string, error) {
+	if e.raw != nil {
+		restore, err := e.raw()
+		if err == nil {
+			defer restore()
+		}
+	}
+
+	e.prompt, e.buf, e.pos = prompt, nil, 0
+	browsing := len(e.history.entries) // history entry shown by up/down, len(entries) is the line being typed
+	typed := ""                        // the line being typed, kept while browsing the history
+	e.refresh()
+
+	for {
+		k, err := e.readKey()
+		if err != nil {
+			if len(e.buf) != 0 {
+				return e.accept(), nil
+			}
+			return "", err
+		}
+
+		if k == ctrlR {
+			if k, err = e.reverseSearch(); err != nil {
+				return "", err
+			}
+		}
+
+		switch k {
+		case enter, ctrlJ:
+			return e.accept(), nil
+		case ctrlC:
+			e.write("^C\n")
+			return "", errInterrupted
+		case ctrlD:
+			if len(e.buf) == 0 {
+				e.write("\n")
+				return "", io.EOF
+			}
+			e.delete(e.pos)
+		case backspace, ctrlH:
+			if e.pos > 0 {
+				e.pos--
+				e.delete(e.pos)
+			}
+		case keyDelete:
+			e.delete(e.pos)
+		case keyLeft, ctrlB:
+			if e.pos > 0 {
+				e.pos--
+			}
+		case keyRight, ctrlF:
+			if e.pos < len(e.buf) {
+				e.pos++
+			}
+		case keyHome, ctrlA:
+			e.pos = 0
+		case keyEnd, ctrlE:
+			e.pos = len(e.buf)
+		case keyUp, ctrlP:
+			if browsing > 0 {
+				if browsing == len(e.history.entries) {
+					typed = string(e.buf)
+				}
+				browsing--
+				e.setLine(e.history.entries[browsing])
+			}
+		case keyDown, ctrlN:
+			if browsing < len(e.history.entries) {
+				browsing++
+				if browsing == len(e.history.entries) {
+					e.setLine(typed)
+				} else {
+					e.setLine(e.history.entries[browsing])
+				}
+			}
+		case ctrlK:
+			e.buf = e.buf[:e.pos]
+		case ctrlU:
+			e.buf = append([]rune{}, e.buf[e.pos:]...)
+			e.pos = 0
+		case ctrlW:
+			e.deleteWord()
+		case ctrlL:
+			e.write("\x1b[H\x1b[2J")
+		case tab:
+			e.completeWord()
+		default:
+			if k < keyNone && unicode.IsPrint(rune(k)) {
+				e.insert([]rune{rune(k)})
+			}
+		}
+		e.refresh()
+	}
+}
+
+// accept - finish the line: redraw it (it may still show the search prompt),
+// move to the next line and remember it in the history
+func (e *editor) accept() string {
+	line := string(e.buf)
+	e.pos = len(e.buf)
+	e.refresh()
+	e.write("\n")
+	e.history.append(line)
+	return line
+}
+
+// readKey - read a rune, decoding the escape sequences of special keys
+func (e *editor) readKey() (key, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if key(r) != escape {
+		return key(r), nil
+	}
+
+	// "\x1b[" starts a control sequence: parameters followed by a final byte (e.g: "\x1b[3~"),
+	// "\x1bO" is followed by a single byte (e.g: "\x1bOH" for home on some terminals)
+	next, err := e.in.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch next {
+	case '[':
+		params := ""
+		for {
+			b, err := e.in.ReadByte()
+			if err != nil {
+				return 0, err
+			}
+			if b >= 0x40 && b <= 0x7e {
+				return csiKey(params, b), nil
+			}
+			params += string(b)
+		}
+	case 'O':
+		b, err := e.in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		return csiKey("", b), nil
+	}
+	return keyNone, nil
+}
+
+// csiKey - the special key for a control sequence with final byte final
+func csiKey(params string, final byte) key {
+	switch final {
+	case 'A':
+		return keyUp
+	case 'B':
+		return keyDown
+	case 'C':
+		return keyRight
+	case 'D':
+		return keyLeft
+	case 'H':
+		return keyHome
+	case 'F':
+		return keyEnd
+	case '~':
+		switch params {
+		case "1", "7":
+			return keyHome
+		case "4", "8":
+			return keyEnd
+		case "3":
+			return keyDelete
+		}
+	}
+	return keyNone
+}
+
+// reverseSearch - Ctrl-R: search the history for the newest entry containing the typed query.
+// Ctrl-R again finds older entries, Ctrl-G or Ctrl-C cancel the search.
+// any other key accepts the match and is returned, so the caller handles it (e.g: enter runs the line)
+func (e *editor) reverseSearch() (key, error) {
+	original, originalPos := e.buf, e.pos
+	query := []rune{}
+	match := -1
+
+	for {
+		e.refreshSearch(string(query), match)
+
+		k, err := e.readKey()
+		if err != nil {
+			return 0, err
+		}
+
+		switch k {
+		case ctrlR:
+			before := len(e.history.entries)
+			if match >= 0 {
+				before = match
+			}
+			if older := e.history.search(string(query), before); older >= 0 {
+				match = older
+			}
+		case backspace, ctrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				match = e.history.search(string(query), len(e.history.entries))
+			}
+		case ctrlG, ctrlC:
+			e.buf, e.pos = original, originalPos
+			return keyNone, nil
+		default:
+			if k < keyNone && unicode.IsPrint(rune(k)) {
+				query = append(query, rune(k))
+				// the current match is kept as long as it still contains the query
+				before := len(e.history.entries)
+				if match >= 0 {
+					before = match + 1
+				}
+				match = e.history.search(string(query), before)
+				continue
+			}
+
+			if match >= 0 {
+				e.setLine(e.history.entries[match])
+			}
+			return k, nil
+		}
+	}
+}
+
+// refreshSearch - draw the search prompt in place of the line, e.g: (reverse-i-search)`add': add(1, 2)
+func (e *editor) refreshSearch(query string, match int) {
+	label := "reverse-i-search"
+	found := ""
+	if match >= 0 {
+		found = e.history.entries[match]
+	} else if query != "" {
+		label = "failing " + label
+	}
+	e.write(fmt.Sprintf("\r(%s)`%s': %s\x1b[K", label, query, found))
+}
+
+// completeWord - complete the word before the cursor. a single candidate is inserted,
+// several candidates are completed to their common prefix, or listed if there is none
+func (e *editor) completeWord() {
+	start := e.pos
+	for start > 0 && isWordRune(e.buf[start-1]) {
+		start--
+	}
+	// REPL commands are completed with their ':'
+	if start == 1 && e.buf[0] == ':' {
+		start = 0
+	}
+	prefix := string(e.buf[start:e.pos])
+	if prefix == "" || e.complete == nil {
+		return
+	}
+
+	candidates := matching(e.complete(), prefix)
+	switch len(candidates) {
+	case 0:
+		e.write("\a")
+	case 1:
+		e.insert([]rune(candidates[0][len(prefix):]))
+	default:
+		common := commonPrefix(candidates)
+		if len(common) > len(prefix) {
+			e.insert([]rune(common[len(prefix):]))
+			return
+		}
+		e.write("\n" + strings.Join(candidates, "  ") + "\n")
+	}
+}
+
+// matching - sorted, unique words starting with prefix
+func matching(words []string, prefix string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) && !seen[word] {
+			seen[word] = true
+			result = append(result, word)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// commonPrefix - longest prefix shared by all words
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// isWordRune - runes identifiers consist of
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// insert - insert runes at the cursor and move the cursor after them
+func (e *editor) insert(runes []rune) {
+	buf := make([]rune, 0, len(e.buf)+len(runes))
+	buf = append(buf, e.buf[:e.pos]...)
+	buf = append(buf, runes...)
+	e.buf = append(buf, e.buf[e.pos:]...)
+	e.pos += len(runes)
+}
+
+// delete - delete the rune at i, if there is one
+func (e *editor) delete(i int) {
+	if i < len(e.buf) {
+		e.buf = append(e.buf[:i], e.buf[i+1:]...)
+	}
+}
+
+// deleteWord - Ctrl-W: delete the word before the cursor, and the spaces after it
+func (e *editor) deleteWord() {
+	start := e.pos
+	for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+		start--
+	}
+	for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+		start--
+	}
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+// setLine - replace the line, the cursor is moved to its end
+func (e *editor) setLine(line string) {
+	e.buf = []rune(line)
+	e.pos = len(e.buf)
+}
+
+// refresh - redraw the prompt and the line, and put the cursor back at its position
+func (e *editor) refresh() {
+	var out strings.Builder
+	out.WriteString("\r")
+	out.WriteString(e.prompt)
+	out.WriteString(string(e.buf))
+	out.WriteString("\x1b[K")
+	if n := len(e.buf) - e.pos; n > 0 {
+		out.WriteString(fmt.Sprintf("\x1b[%dD", n))
+	}
+	e.write(out.String())
+}
+
+func (e *editor) write(s string) {
+	io.WriteString(e.out, s)
+}
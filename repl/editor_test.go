@@ -0,0 +1,219 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"monkeylang/object"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(input string, entries ...string) (*editor, *bytes.Buffer) {
+	var out bytes.Buffer
+	h := &history{}
+	for _, entry := range entries {
+		h.add(entry)
+	}
+	completions := []string{"let", "len", "length", "puts", ":help", ":env"}
+	e := newEditor(strings.NewReader(input), &out, h, func() []string { return completions })
+	return e, &out
+}
+
+func TestEditorEditing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"abc\r", "abc"},
+		{"abc\n", "abc"},
+		{"abc\x1b[D\x1b[DX\r", "aXbc"},
+		{"abc\x02\x02\x7f\r", "bc"},
+		{"abc\x02\x02\x08\r", "bc"},
+		{"abc\x01X\r", "Xabc"},
+		{"abc\x01\x1b[3~\r", "bc"},
+		{"abc\x01\x04\r", "bc"},
+		{"abc\x04\r", "abc"},
+		{"abc\x01\x05d\r", "abcd"},
+		{"abc\x01\x1b[C\x06X\r", "abXc"},
+		{"a\x1b[1~b\x1b[4~c\r", "bac"},
+		{"a\x1b[Hb\x1b[Fc\r", "bac"},
+		{"ab\x1bOHc\r", "cab"},
+		{"let x = 1\x17\x17\r", "let x "},
+		{"hello world\x01\x06\x06\x0b\r", "he"},
+		{"hello world\x1b[D\x1b[D\x15\r", "ld"},
+		{"größe\x7f\r", "größ"},
+		{"a\x1b[5~b\x1bxc\r", "abc"}, // unknown sequences are ignored
+		{"a\x0cb\r", "ab"},
+	}
+
+	for _, tt := range tests {
+		e, _ := newTestEditor(tt.input)
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("input %q: unexpected error %v", tt.input, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("input %q: wrong line. expected=%q, got=%q", tt.input, tt.expected, line)
+		}
+	}
+}
+
+func TestEditorEndOfInput(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedLine string
+		expectedErr  error
+	}{
+		{"\x04", "", io.EOF},
+		{"", "", io.EOF},
+		{"abc\x03", "", errInterrupted},
+		{"abc", "abc", nil},
+	}
+
+	for _, tt := range tests {
+		e, _ := newTestEditor(tt.input)
+		line, err := e.readLine(PROMPT)
+		if err != tt.expectedErr {
+			t.Errorf("input %q: wrong error. expected=%v, got=%v", tt.input, tt.expectedErr, err)
+		}
+		if line != tt.expectedLine {
+			t.Errorf("input %q: wrong line. expected=%q, got=%q", tt.input, tt.expectedLine, line)
+		}
+	}
+}
+
+func TestEditorRefresh(t *testing.T) {
+	e, out := newTestEditor("ab\x1b[D")
+	e.readLine(PROMPT)
+
+	expected := "\r" + PROMPT + "ab\x1b[K\x1b[1D"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("cursor is not moved back. expected output to contain %q, got=%q", expected, out.String())
+	}
+}
+
+func TestEditorHistory(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"\x1b[A\r", "second"},
+		{"\x1b[A\x1b[A\r", "first"},
+		{"\x1b[A\x1b[A\x1b[A\r", "first"},
+		{"\x10\x10\x0e\r", "second"},
+		{"typed\x1b[A\x1b[A\x1b[B\x1b[B\r", "typed"},
+		{"\x1b[B\r", ""},
+	}
+
+	for _, tt := range tests {
+		e, _ := newTestEditor(tt.input, "first", "second")
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("input %q: unexpected error %v", tt.input, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("input %q: wrong line. expected=%q, got=%q", tt.input, tt.expected, line)
+		}
+	}
+
+	e, _ := newTestEditor("third\r\r\x1b[A\r", "first", "second")
+	for i := 0; i < 3; i++ {
+		if _, err := e.readLine(PROMPT); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	expected := "first,second,third"
+	if got := strings.Join(e.history.entries, ","); got != expected {
+		t.Errorf("wrong history. expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestEditorReverseSearch(t *testing.T) {
+	entries := []string{"let add = fn(a, b) { a + b };", "add(1, 2)", "let x = 5;"}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"\x12add\r", "add(1, 2)"},
+		{"\x12add\x12\r", "let add = fn(a, b) { a + b };"},
+		{"\x12add\x12\x12\r", "let add = fn(a, b) { a + b };"},
+		{"\x12let\r", "let x = 5;"},
+		{"\x12let a\r", "let add = fn(a, b) { a + b };"},
+		{"\x12let a\x7f\x7f\r", "let x = 5;"},
+		{"\x12add\x1b[D!\r", "add(1, 2!)"},
+		{"\x12add\x01!\r", "!add(1, 2)"},
+		{"abc\x12add\x07\r", "abc"},
+		{"abc\x12add\x03\r", "abc"},
+		{"abc\x12xyz\r", "abc"},
+	}
+
+	for _, tt := range tests {
+		e, _ := newTestEditor(tt.input, entries...)
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("input %q: unexpected error %v", tt.input, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("input %q: wrong line. expected=%q, got=%q", tt.input, tt.expected, line)
+		}
+	}
+
+	e, out := newTestEditor("\x12xyz\x07\r", entries...)
+	e.readLine(PROMPT)
+	if !strings.Contains(out.String(), "(failing reverse-i-search)`xyz': ") {
+		t.Errorf("failed search is not shown. got=%q", out.String())
+	}
+}
+
+func TestEditorCompletion(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedLine   string
+		expectedOutput string
+	}{
+		{"pu\t\r", "puts", ""},
+		{"leng\t\r", "length", ""},
+		{"puts(leng\t)\r", "puts(length)", ""},
+		{"pu\x01\x06\x06\t\r", "puts", ""},
+		{"l\t\r", "le", ""},
+		{"le\t\r", "le", "\nlen  length  let\n"},
+		{"len\t\r", "len", "\nlen  length\n"},
+		{":he\t\r", ":help", ""},
+		{"x\t\r", "x", "\a"},
+		{"\t\r", "", ""},
+	}
+
+	for _, tt := range tests {
+		e, out := newTestEditor(tt.input)
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("input %q: unexpected error %v", tt.input, err)
+			continue
+		}
+		if line != tt.expectedLine {
+			t.Errorf("input %q: wrong line. expected=%q, got=%q", tt.input, tt.expectedLine, line)
+		}
+		if !strings.Contains(out.String(), tt.expectedOutput) {
+			t.Errorf("input %q: wrong output. expected to contain %q, got=%q", tt.input, tt.expectedOutput, out.String())
+		}
+	}
+}
+
+func TestSessionCompletions(t *testing.T) {
+	s := &session{out: &bytes.Buffer{}, env: newTestEnv()}
+	words := strings.Join(s.completions(), " ")
+	for _, word := range []string{"fn", "return", "len", "puts", "answer", ":help", ":quit", ":load"} {
+		if !strings.Contains(" "+words+" ", " "+word+" ") {
+			t.Errorf("%q is not completed. got=%q", word, words)
+		}
+	}
+}
+
+func newTestEnv() *object.Environment {
+	env := object.NewEnvironment()
+	env.Set("answer", &object.Integer{Value: 42})
+	return env
+}
@@ -0,0 +1,126 @@
+package repl
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HISTORY_FILE - name of the history file in the home directory
+const HISTORY_FILE = ".monkey_history"
+
+// maxHistory - number of entries kept in memory and loaded from the history file
+const maxHistory = 1000
+
+// maxHistoryFile - the history file is rewritten with the last maxHistory entries
+// once it has more lines than this, so it does not grow forever
+const maxHistoryFile = 2 * maxHistory
+
+// history - lines entered in the line editor, oldest first.
+// if path is set, every new entry is appended to that file
+type history struct {
+	entries   []string
+	path      string
+	fileLines int // number of lines in the history file
+}
+
+// historyPath - ~/.monkey_history, empty if there is no home directory
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, HISTORY_FILE)
+}
+
+// loadHistory - read the history file at path, one entry per line.
+// a missing file is an empty history
+func loadHistory(path string) (*history, error) {
+	h := &history{path: path}
+	if path == "" {
+		return h, nil
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.fileLines++
+		h.add(scanner.Text())
+	}
+	return h, scanner.Err()
+}
+
+// add - add an entry in memory. empty lines and repetitions of the last entry are skipped
+func (h *history) add(line string) bool {
+	if strings.TrimSpace(line) == "" {
+		return false
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return false
+	}
+
+	h.entries = append(h.entries, line)
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+	}
+	return true
+}
+
+// append - add an entry and append it to the history file
+func (h *history) append(line string) error {
+	if !h.add(line) || h.path == "" {
+		return nil
+	}
+	if h.fileLines >= maxHistoryFile {
+		return h.rewrite()
+	}
+
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	h.fileLines++
+	return f.Close()
+}
+
+// rewrite - replace the history file with the entries in memory. the new file is
+// written next to the old one first, so the history is not lost if writing fails
+func (h *history) rewrite() error {
+	content := strings.Join(h.entries, "\n") + "\n"
+	tmp := h.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(content), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return err
+	}
+	h.fileLines = len(h.entries)
+	return nil
+}
+
+// search - index of the newest entry before 'before' that contains query, -1 if there is none
+func (h *history) search(query string, before int) int {
+	if before > len(h.entries) {
+		before = len(h.entries)
+	}
+	for i := before - 1; i >= 0; i-- {
+		if strings.Contains(h.entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,132 @@
+package repl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+
+	h, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("missing history file is an error: %v", err)
+	}
+	if len(h.entries) != 0 {
+		t.Fatalf("missing history file is not empty. got=%v", h.entries)
+	}
+
+	for _, line := range []string{"let x = 1;", "", "  ", "x", "x", "let x = 1;"} {
+		if err := h.append(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "let x = 1;\nx\nlet x = 1;\n"
+	if string(content) != expected {
+		t.Errorf("wrong history file. expected=%q, got=%q", expected, string(content))
+	}
+
+	loaded, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.entries) != 3 || loaded.entries[2] != "let x = 1;" {
+		t.Errorf("wrong entries loaded. got=%q", loaded.entries)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	h := &history{}
+	for i := 0; i < maxHistory+10; i++ {
+		h.add(fmt.Sprintf("%d", i))
+	}
+
+	if len(h.entries) != maxHistory {
+		t.Fatalf("wrong number of entries. expected=%d, got=%d", maxHistory, len(h.entries))
+	}
+	if h.entries[0] != "10" {
+		t.Errorf("oldest entries are not dropped. got=%q", h.entries[0])
+	}
+}
+
+func TestHistoryFileLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+
+	h, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3*maxHistory; i++ {
+		if err := h.append(fmt.Sprintf("%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(lines) > maxHistoryFile {
+		t.Fatalf("history file is not truncated. got=%d lines", len(lines))
+	}
+	if last := lines[len(lines)-1]; last != fmt.Sprintf("%d", 3*maxHistory-1) {
+		t.Errorf("newest entry is missing from the history file. got=%q", last)
+	}
+
+	// a file that grew too long (e.g: written by an older version) is truncated on the next entry
+	var long strings.Builder
+	for i := 0; i < 3*maxHistory; i++ {
+		fmt.Fprintf(&long, "%d\n", i)
+	}
+	if err := ioutil.WriteFile(path, []byte(long.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.append("new"); err != nil {
+		t.Fatal(err)
+	}
+	content, err = ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(lines) != maxHistory || lines[0] != fmt.Sprintf("%d", 2*maxHistory+1) || lines[maxHistory-1] != "new" {
+		t.Errorf("history file is not truncated to the last %d entries. got=%d lines, first=%q", maxHistory, len(lines), lines[0])
+	}
+}
+
+func TestHistorySearch(t *testing.T) {
+	h := &history{entries: []string{"let a = 1;", "a + 1", "let b = 2;"}}
+
+	tests := []struct {
+		query    string
+		before   int
+		expected int
+	}{
+		{"let", 3, 2},
+		{"let", 2, 0},
+		{"let", 100, 2},
+		{"a", 3, 1},
+		{"a", 1, 0},
+		{"a", 0, -1},
+		{"c", 3, -1},
+	}
+
+	for _, tt := range tests {
+		if got := h.search(tt.query, tt.before); got != tt.expected {
+			t.Errorf("search(%q, %d) wrong. expected=%d, got=%d", tt.query, tt.before, tt.expected, got)
+		}
+	}
+}
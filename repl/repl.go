@@ -4,28 +4,263 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"monkeylang/evaluator"
 	"monkeylang/lexer"
+	"monkeylang/object"
+	"monkeylang/parser"
 	"monkeylang/token"
+	"os"
+	"strings"
 )
 
 const PROMPT = "☄ | "
 
-// Start - read from input source until you find a newline
-// take the line, pass it to a lexer instance, print all tokens lexer returns until EOF
+// CONTINUATION_PROMPT - shown while the input so far is incomplete (e.g: an unclosed '{')
+const CONTINUATION_PROMPT = "  | "
+
+// mode - what the REPL does with a line of input
+type mode int
+
+const (
+	evalMode   mode = iota // parse and evaluate, print the result
+	tokensMode             // print every token the lexer returns
+	astMode                // print the parsed program
+)
+
+// session - state of the REPL that survives across inputs
+type session struct {
+	out     io.Writer
+	env     *object.Environment
+	mode    mode
+	history []string // inputs evaluated without errors, written to a file by :save
+}
+
+// lineReader - source of input lines: a line editor on terminals, a bufio.Scanner otherwise
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
+// scannerReader - reads lines with a bufio.Scanner (e.g: when the input is piped into the REPL)
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scannerReader) readLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// newLineReader - use the line editor if both in and out are terminals
+func newLineReader(in io.Reader, out io.Writer, s *session) lineReader {
+	inFile, inOK := in.(*os.File)
+	outFile, outOK := out.(*os.File)
+	if !inOK || !outOK || !isTerminal(inFile.Fd()) || !isTerminal(outFile.Fd()) {
+		return &scannerReader{scanner: bufio.NewScanner(in), out: out}
+	}
+
+	// the history still works for this session if the file can not be read
+	h, _ := loadHistory(historyPath())
+	e := newEditor(in, out, h, s.completions)
+	e.raw = func() (func() error, error) { return makeRaw(inFile.Fd()) }
+	return e
+}
+
+// Start - read from input source until the input forms a complete statement, evaluate it
+// and print the result. lines are collected as long as the input is incomplete (see incomplete),
+// two empty lines in a row evaluate it anyway.
+// bindings are kept in one environment, so they survive across inputs.
+// lines starting with ':' are REPL commands (see :help).
+// on terminals lines are read with a line editor, with history (~/.monkey_history) and tab completion.
+// the output of puts goes to out as well
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+	s := &session{out: out}
+	s.env = s.newEnvironment()
+	reader := newLineReader(in, out, s)
 
+	var lines []string
 	for {
-		fmt.Fprintf(out, PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		prompt := PROMPT
+		if len(lines) != 0 {
+			prompt = CONTINUATION_PROMPT
+		}
+		line, err := reader.readLine(prompt)
+		if err == errInterrupted {
+			lines = nil
+			continue
+		}
+		if err != nil {
+			// report the errors of whatever was left incomplete
+			if len(lines) != 0 {
+				fmt.Fprintln(out)
+				s.handle(strings.Join(lines, "\n"))
+			}
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-			fmt.Fprintf(out, "%+v\n", tok)
+		if len(lines) == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			if quit := s.command(strings.TrimSpace(line)); quit {
+				return
+			}
+			continue
+		}
+
+		lines = append(lines, line)
+		input := strings.Join(lines, "\n")
+		if incomplete(input) && !endsWithEmptyLines(lines) {
+			continue
+		}
+		lines = nil
+
+		s.handle(input)
+	}
+}
+
+// completions - words the line editor completes: keywords, builtins, bindings and REPL commands
+func (s *session) completions() []string {
+	words := token.Keywords()
+	words = append(words, evaluator.BuiltinNames()...)
+	words = append(words, s.env.Names()...)
+	for _, c := range commandHelp {
+		words = append(words, strings.Fields(c[0])[0])
+	}
+	return words
+}
+
+// handle - handle a complete input according to the current mode
+func (s *session) handle(input string) {
+	switch s.mode {
+	case tokensMode:
+		printTokens(s.out, input)
+	case astMode:
+		printAST(s.out, input)
+	default:
+		s.evalInput(input)
+	}
+}
+
+// endsWithEmptyLines - the user typed two empty lines in a row after an incomplete input
+func endsWithEmptyLines(lines []string) bool {
+	n := len(lines)
+	return n >= 3 && strings.TrimSpace(lines[n-1]) == "" && strings.TrimSpace(lines[n-2]) == ""
+}
+
+// continuationTokens - an input ending in one of these is continued on the next line (e.g: '1 +')
+var continuationTokens = map[token.TokenType]bool{
+	token.ASSIGN:   true,
+	token.PLUS:     true,
+	token.MINUS:    true,
+	token.BANG:     true,
+	token.ASTERISK: true,
+	token.SLASH:    true,
+	token.PERCENT:  true,
+	token.LT:       true,
+	token.GT:       true,
+	token.LT_EQ:    true,
+	token.GT_EQ:    true,
+	token.EQ:       true,
+	token.NOT_EQ:   true,
+	token.AND:      true,
+	token.OR:       true,
+	token.COMMA:    true,
+	token.COLON:    true,
+}
+
+// incomplete - reports whether more input could complete input: it ends inside a string or
+// block comment, has unclosed brackets, ends in an operator, or the parser ran into EOF
+func incomplete(input string) bool {
+	l := lexer.New(input)
+	depth := 0
+	var last token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth++
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth--
+		}
+		last = tok
+	}
+
+	if l.Unfinished() || depth > 0 {
+		return true
+	}
+	// too many closing brackets can not be fixed by typing more
+	if depth < 0 {
+		return false
+	}
+	if continuationTokens[last.Type] {
+		return true
+	}
+
+	p := parser.New(lexer.New(input))
+	p.ParseProgram()
+	for _, err := range p.Errors() {
+		if err.Got == token.EOF {
+			return true
 		}
 	}
+	return false
+}
+
+// printTokens - print all tokens the lexer returns until EOF
+func printTokens(out io.Writer, input string) {
+	l := lexer.New(input)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(out, "%+v\n", tok)
+	}
+}
+
+// printAST - print the parsed program, or the parse errors
+func printAST(out io.Writer, input string) {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		fmt.Fprint(out, parser.RenderErrors(input, p.Errors()))
+		return
+	}
+	fmt.Fprintln(out, program.String())
+}
+
+// newEnvironment - empty environment, builtins like puts write to the REPL output
+func (s *session) newEnvironment() *object.Environment {
+	env := object.NewEnvironment()
+	env.SetOutput(s.out)
+	return env
+}
+
+// eval - like evaluate, but inputs evaluated without errors are added to the history
+func (s *session) eval(filename, source string) (result object.Object, ok bool) {
+	evaluated, ok := s.evaluate(filename, source)
+	if _, isError := evaluated.(*object.Error); ok && !isError {
+		s.history = append(s.history, source)
+	}
+	return evaluated, ok
+}
+
+// evaluate - parse and evaluate source in the session environment. nothing is evaluated if there
+// are parse errors, they are printed and ok is false
+func (s *session) evaluate(filename, source string) (result object.Object, ok bool) {
+	p := parser.New(lexer.NewFile(filename, source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		fmt.Fprint(s.out, parser.RenderErrors(source, p.Errors()))
+		return nil, false
+	}
+
+	return evaluator.Eval(program, s.env), true
+}
+
+// evalInput - evaluate input and print the result
+func (s *session) evalInput(input string) {
+	evaluated, _ := s.eval("", input)
+	if evaluated != nil {
+		fmt.Fprintln(s.out, evaluated.Inspect())
+	}
 }
@@ -0,0 +1,127 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func run(input string) string {
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+	return strings.ReplaceAll(out.String(), PROMPT, "")
+}
+
+func TestEvaluate(t *testing.T) {
+	input := `let x = 5;
+let add = fn(a, b) { a + b };
+add(x, 10)
+puts("hi")
+x + true
+`
+	expected := `15
+hi
+null
+TypeError: type mismatch: INTEGER + BOOLEAN at 1:3
+`
+	if got := run(input); got != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	expected := `1:7: error[P001]: expected next token to be =, got INT instead
+let x 5;
+      ^
+`
+	if got := run("let x 5;\n"); got != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestModeToggle(t *testing.T) {
+	input := `:ast
+1 + 2 * 3
+:ast
+:tokens
+:tokens
+1 + 2
+`
+	expected := `printing the AST
+(1 + (2 * 3))
+evaluating input
+printing tokens
+evaluating input
+3
+`
+	if got := run(input); got != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, got)
+	}
+
+	got := run(":tokens\nlet\n")
+	if !strings.Contains(got, "{Type:LET Literal:let") {
+		t.Errorf("tokens are not printed. got=%q", got)
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 + 2", false},
+		{"let x = 5;", false},
+		{"", false},
+		{"let add = fn(a, b) {", true},
+		{"let add = fn(a, b) {\n  a + b\n};", false},
+		{"add(1,", true},
+		{"[1, 2", true},
+		{`{"a": 1`, true},
+		{"1 +", true},
+		{"true &&", true},
+		{"let x =", true},
+		{"let x", true},
+		{"if (x)", true},
+		{"if (x) { 1 } else", true},
+		{`"unterminated`, true},
+		{"/* comment", true},
+		{"let x 5;", false},
+		{"1 + 2)", false},
+		{"1 +)", false},
+	}
+
+	for _, tt := range tests {
+		if got := incomplete(tt.input); got != tt.expected {
+			t.Errorf("input %q: wrong result. expected=%t, got=%t", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestMultiLineInput(t *testing.T) {
+	var out bytes.Buffer
+	input := "let add = fn(a, b) {\n\n  a +\n    b\n};\nadd(1,\n2)\n"
+	Start(strings.NewReader(input), &out)
+
+	expected := PROMPT + strings.Repeat(CONTINUATION_PROMPT, 4) +
+		PROMPT + CONTINUATION_PROMPT + "3\n" + PROMPT
+	if out.String() != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestForcedEvaluation(t *testing.T) {
+	// two empty lines give up on an incomplete input and report the errors
+	got := run("let x = (1 +\n\n\nlet y = 2;\ny\n")
+	if !strings.Contains(got, "error[P") {
+		t.Errorf("parse errors are not reported. got=%q", got)
+	}
+	if !strings.HasSuffix(got, "2\n") {
+		t.Errorf("input after forced evaluation is not evaluated. got=%q", got)
+	}
+
+	// incomplete input at the end of the input is reported as well
+	got = run("let x = fn() {\n")
+	if !strings.Contains(got, "error[P005]") {
+		t.Errorf("unclosed block is not reported. got=%q", got)
+	}
+}
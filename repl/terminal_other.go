@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package repl
+
+import "errors"
+
+// isTerminal - raw mode is not supported on this platform, so input is always read line by line
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (restore func() error, err error) {
+	return nil, errors.New("raw mode is not supported on this platform")
+}
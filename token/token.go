@@ -1,19 +1,57 @@
 package token
 
+import (
+	"fmt"
+	"sort"
+)
+
 // TokenType - many different values as tokentypes
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // position of the first character of the token
+	End     Position // position right after the last character of the token
+}
+
+// Position - location of a character in the source code.
+// Line and Column start at 1, Offset is the byte offset (starting at 0)
+type Position struct {
+	Filename string // empty when the source does not come from a file (e.g: the REPL)
+	Offset   int
+	Line     int
+	Column   int
+}
+
+// IsValid - the zero value of Position is not a valid position
+func (pos Position) IsValid() bool { return pos.Line > 0 }
+
+// String - formats a position as file:line:column (or line:column if there is no file name)
+func (pos Position) String() string {
+	s := pos.Filename
+	if !pos.IsValid() {
+		if s == "" {
+			s = "-"
+		}
+		return s
+	}
+	if s != "" {
+		s += ":"
+	}
+	return s + fmt.Sprintf("%d:%d", pos.Line, pos.Column)
 }
 
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
 	// identifiers and literals
-	IDENT = "IDENT"
-	INT   = "INT"
+	IDENT  = "IDENT"
+	INT    = "INT"
+	FLOAT  = "FLOAT"
+	STRING = "STRING"
+	// comments are only returned by the lexer in lexer.ScanComments mode
+	COMMENT = "COMMENT"
 	// operators
 	ASSIGN   = "="
 	PLUS     = "+"
@@ -21,21 +59,31 @@ const (
 	BANG     = "!"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
 
 	LT     = "<"
 	GT     = ">"
+	LT_EQ  = "<="
+	GT_EQ  = ">="
 	EQ     = "=="
 	NOT_EQ = "!="
 
+	AND = "&&"
+	OR  = "||"
+
 	// delimiter
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
 
 	LPAREN = "("
 	RPAREN = ")"
 	LBRACE = "{"
 	RBRACE = "}"
 
+	LBRACKET = "["
+	RBRACKET = "]"
+
 	// keywords
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
@@ -64,3 +112,13 @@ func LookupIdent(ident string) TokenType {
 	}
 	return IDENT
 }
+
+// Keywords - all keywords of the language, sorted (e.g: for completion in the REPL)
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
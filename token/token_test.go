@@ -0,0 +1,37 @@
+package token
+
+import "testing"
+
+func TestPositionString(t *testing.T) {
+	tests := []struct {
+		pos      Position
+		expected string
+	}{
+		{Position{Filename: "script.mk", Line: 3, Column: 7}, "script.mk:3:7"},
+		{Position{Line: 3, Column: 7}, "3:7"},
+		{Position{Filename: "script.mk"}, "script.mk"},
+		{Position{}, "-"},
+	}
+
+	for _, tt := range tests {
+		if tt.pos.String() != tt.expected {
+			t.Errorf("position string wrong. expected=%q, got=%q", tt.expected, tt.pos.String())
+		}
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	expected := []string{"else", "false", "fn", "if", "let", "return", "true"}
+	keywords := Keywords()
+	if len(keywords) != len(expected) {
+		t.Fatalf("wrong number of keywords. expected=%d, got=%d (%v)", len(expected), len(keywords), keywords)
+	}
+	for i, word := range expected {
+		if keywords[i] != word {
+			t.Errorf("keywords[%d] wrong. expected=%q, got=%q", i, word, keywords[i])
+		}
+		if LookupIdent(word) == IDENT {
+			t.Errorf("%q is not looked up as a keyword", word)
+		}
+	}
+}